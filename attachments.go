@@ -0,0 +1,140 @@
+package redmine
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type attachmentResult struct {
+	Attachment Attachment `json:"attachment"`
+}
+
+type attachmentRequest struct {
+	Attachment attachmentUpdate `json:"attachment"`
+}
+
+// attachmentUpdate holds the only attributes Redmine allows to change.
+type attachmentUpdate struct {
+	Filename    string `json:"filename,omitempty"`
+	Description string `json:"description"`
+}
+
+type Attachment struct {
+	Id           int     `json:"id"`
+	Filename     string  `json:"filename"`
+	Filesize     int64   `json:"filesize"`
+	ContentType  string  `json:"content_type"`
+	Description  string  `json:"description"`
+	ContentUrl   string  `json:"content_url"`
+	ThumbnailUrl string  `json:"thumbnail_url,omitempty"`
+	Digest       string  `json:"digest,omitempty"`
+	Author       *IdName `json:"author,omitempty"`
+	CreatedOn    string  `json:"created_on"`
+}
+
+func (c *Client) Attachment(id int) (*Attachment, error) {
+	req, err := c.NewRequest("GET", "/attachments/"+strconv.Itoa(id)+".json", nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	decoder := json.NewDecoder(res.Body)
+	var r attachmentResult
+	if res.StatusCode == 404 {
+		return nil, errors.New("Not Found")
+	}
+	if res.StatusCode != 200 {
+		err = errorFromResp(decoder, res.StatusCode)
+	} else {
+		err = decoder.Decode(&r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r.Attachment, nil
+}
+
+// DownloadAttachment writes the content of the attachment to w.
+func (c *Client) DownloadAttachment(id int, w io.Writer) error {
+	attachment, err := c.Attachment(id)
+	if err != nil {
+		return err
+	}
+	req, err := c.NewRequest("GET", attachment.ContentUrl, nil)
+	if err != nil {
+		return err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return errors.New("Not Found")
+	}
+	if res.StatusCode != 200 {
+		return errorFromResp(json.NewDecoder(res.Body), res.StatusCode)
+	}
+	_, err = io.Copy(w, res.Body)
+	return err
+}
+
+// UpdateAttachment changes the filename and description of the attachment.
+// An empty Filename keeps the current one.
+func (c *Client) UpdateAttachment(attachment Attachment) error {
+	var ar attachmentRequest
+	ar.Attachment.Filename = attachment.Filename
+	ar.Attachment.Description = attachment.Description
+	s, err := json.Marshal(ar)
+	if err != nil {
+		return err
+	}
+	req, err := c.NewRequest("PATCH", "/attachments/"+strconv.Itoa(attachment.Id)+".json", strings.NewReader(string(s)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return errors.New("Not Found")
+	}
+	if res.StatusCode/100 != 2 {
+		err = errorFromResp(json.NewDecoder(res.Body), res.StatusCode)
+	}
+	return err
+}
+
+func (c *Client) DeleteAttachment(id int) error {
+	req, err := c.NewRequest("DELETE", "/attachments/"+strconv.Itoa(id)+".json", strings.NewReader(""))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return errors.New("Not Found")
+	}
+	if res.StatusCode/100 != 2 {
+		err = errorFromResp(json.NewDecoder(res.Body), res.StatusCode)
+	}
+	return err
+}
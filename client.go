@@ -64,6 +64,55 @@ func (c *Client) getPaginationClause() string {
 	return strings.Join(clauses, "&")
 }
 
+// getAllPages reads the listing at path page by page, following total_count
+// with offset like getIssues, until all items have been read. Pages hold
+// Limit items if set. decode reads a page and returns the number of items
+// it held and the total count.
+func (c *Client) getAllPages(path string, decode func(decoder *json.Decoder) (n int, total int, err error)) error {
+	sep := "?"
+	if strings.HasSuffix(path, "?") || strings.HasSuffix(path, "&") {
+		sep = ""
+	} else if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	read := 0
+	for {
+		uri := path + sep + "offset=" + strconv.Itoa(read)
+		if c.Limit > -1 {
+			uri += "&limit=" + strconv.Itoa(c.Limit)
+		}
+		n, total, err := c.getPage(uri, decode)
+		if err != nil {
+			return err
+		}
+		read += n
+		if n == 0 || read >= total {
+			return nil
+		}
+	}
+}
+
+func (c *Client) getPage(uri string, decode func(decoder *json.Decoder) (int, int, error)) (int, int, error) {
+	req, err := c.NewRequest("GET", uri, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return 0, 0, errors.New("Not Found")
+	}
+	decoder := json.NewDecoder(res.Body)
+	if res.StatusCode != 200 {
+		return 0, 0, errorFromResp(decoder, res.StatusCode)
+	}
+	return decode(decoder)
+}
+
 type errorsResult struct {
 	Errors []string `json:"errors"`
 }
@@ -94,7 +143,7 @@ type Id struct {
 func (c *Client) NewRequest(method string, urlPath string, body io.Reader) (*http.Request, error) {
 
 	// Hack to avoid changing how URLWithFilter works.
-	if !strings.HasPrefix("http://", urlPath) && !strings.HasPrefix("https://", urlPath) {
+	if !strings.HasPrefix(urlPath, "http://") && !strings.HasPrefix(urlPath, "https://") {
 		a := strings.TrimRight(c.endpoint, "/")
 		b := strings.TrimLeft(urlPath, "/")
 		urlPath = a + "/" + b
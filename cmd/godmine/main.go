@@ -1,7 +1,9 @@
 package main
 
 import (
+	"bufio"
 	"crypto/tls"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -15,6 +17,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -22,6 +25,7 @@ import (
 	"github.com/mattn/go-shellwords"
 
 	"bsky.watch/redmine"
+	"bsky.watch/redmine/gen"
 )
 
 const name = "godmine"
@@ -33,9 +37,12 @@ var revision = "HEAD"
 type config struct {
 	Endpoint string `json:"endpoint"`
 	Apikey   string `json:"apikey"`
-	Project  int    `json:"project"`
+	Project  int    `json:"-"`
 	Editor   string `json:"editor"`
 	Insecure bool   `json:"insecure"`
+
+	// ProjectRef is the project id, or identifier, as in the file.
+	ProjectRef interface{} `json:"project"`
 }
 
 var (
@@ -244,13 +251,40 @@ func getConfig() config {
 	if err != nil {
 		fatal("Failed to unmarshal file: %s\n", err)
 	}
+	switch ref := c.ProjectRef.(type) {
+	case float64:
+		c.Project = int(ref)
+	case string:
+		c.Project, _ = strconv.Atoi(ref)
+	}
 	return c
 }
 
+// currentProject returns the id of the project of the configuration,
+// looking it up on first use if it is given by identifier.
+func currentProject() int {
+	ref, ok := conf.ProjectRef.(string)
+	if !ok || conf.Project != 0 || ref == "" {
+		return conf.Project
+	}
+	conf.Project = projectArg(ref)
+	return conf.Project
+}
+
+// projectArg returns the id of the project given by id or identifier.
+func projectArg(ref string) int {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	id, err := redmine.NewResolver(c).ProjectId(ref)
+	if err != nil {
+		fatal("Failed to resolve project: %s\n", err)
+	}
+	return id
+}
+
 func addIssue(subject, description string) {
 	var issue redmine.Issue
 	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
-	issue.ProjectId = conf.Project
+	issue.ProjectId = currentProject()
 	issue.Subject = subject
 	issue.Description = description
 	_, err := c.CreateIssue(issue)
@@ -265,7 +299,7 @@ func createIssue() {
 		fatal("%s\n", err)
 	}
 	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
-	issue.ProjectId = conf.Project
+	issue.ProjectId = currentProject()
 	_, err = c.CreateIssue(*issue)
 	if err != nil {
 		fatal("Failed to create issue: %s\n", err)
@@ -284,7 +318,7 @@ func updateIssue(id int) {
 	}
 	issue.Subject = issueNew.Subject
 	issue.Description = issueNew.Description
-	issue.ProjectId = conf.Project
+	issue.ProjectId = currentProject()
 	err = c.UpdateIssue(*issue)
 	if err != nil {
 		fatal("Failed to update issue: %s\n", err)
@@ -333,7 +367,7 @@ func notesIssue(id int) {
 		fatal("%s\n", err)
 	}
 	issue.Notes = content
-	issue.ProjectId = conf.Project
+	issue.ProjectId = currentProject()
 	err = c.UpdateIssue(*issue)
 	if err != nil {
 		fatal("Failed to update issue: %s\n", err)
@@ -389,6 +423,400 @@ func listIssues(filter *redmine.IssueFilter) {
 	}
 }
 
+func treeIssue(id int) {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	root, err := c.IssueTree(id)
+	if err != nil {
+		fatal("Failed to get issue tree: %s\n", err)
+	}
+	root.Walk(func(node *redmine.IssueNode, depth int) {
+		status := ""
+		if node.Issue.Status != nil {
+			status = node.Issue.Status.Name
+		}
+		fmt.Printf("%s%4d: %s [%s] %3.0f%% %.1fh/%.1fh\n",
+			strings.Repeat("  ", depth),
+			node.Issue.Id,
+			node.Issue.Subject,
+			status,
+			node.DoneRatio,
+			node.SpentHours,
+			node.EstimatedHours)
+	})
+}
+
+func hours(d time.Duration) string {
+	return strconv.FormatFloat(d.Hours(), 'f', 1, 64)
+}
+
+func metricsIssues(args []string) {
+	fs := flag.NewFlagSet("metrics", flag.ExitOnError)
+	summary := fs.String("summary", "", "aggregate by 'tracker' or 'project'")
+	inProgress := fs.String("in-progress", "", "comma separated statuses starting the cycle time")
+	fs.Parse(args)
+	group := redmine.MetricsByTracker
+	switch *summary {
+	case "", "tracker":
+	case "project":
+		group = redmine.MetricsByProject
+	default:
+		fatal("Invalid summary: %s\n", errors.New(*summary))
+	}
+
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	statuses, err := c.IssueStatuses()
+	if err != nil {
+		fatal("Failed to get issue statuses: %s\n", err)
+	}
+	opts := &redmine.MetricsOptions{
+		ClosedStatuses: redmine.ClosedStatusIds(statuses),
+	}
+	if *inProgress != "" {
+		for _, v := range strings.Split(*inProgress, ",") {
+			found := false
+			for _, s := range statuses {
+				if strconv.Itoa(s.Id) == v || strings.EqualFold(s.Name, v) {
+					opts.InProgressStatuses = append(opts.InProgressStatuses, s.Id)
+					found = true
+				}
+			}
+			if !found {
+				fatal("Unknown status: %s\n", errors.New(v))
+			}
+		}
+	}
+	issues, err := c.IssuesWithJournals(&redmine.IssueFilter{
+		ProjectId: fmt.Sprint(currentProject()),
+		StatusId:  "*",
+	})
+	if err != nil {
+		fatal("Failed to list issues: %s\n", err)
+	}
+	var metrics []*redmine.IssueMetrics
+	for i := range issues {
+		m, err := redmine.ComputeIssueMetrics(&issues[i], opts)
+		if err != nil {
+			fatal("Failed to compute metrics: %s\n", err)
+		}
+		metrics = append(metrics, m)
+	}
+
+	w := csv.NewWriter(os.Stdout)
+	defer w.Flush()
+	if *summary != "" {
+		header := []string{"group", "issues", "closed",
+			"lead_p50_h", "lead_p85_h", "lead_p95_h",
+			"cycle_p50_h", "cycle_p85_h", "cycle_p95_h"}
+		for _, s := range statuses {
+			header = append(header, s.Name+"_p50_h", s.Name+"_p85_h", s.Name+"_p95_h")
+		}
+		w.Write(header)
+		for _, s := range redmine.SummarizeMetrics(metrics, group) {
+			record := []string{s.Group, strconv.Itoa(s.Issues), strconv.Itoa(s.LeadTime.Count),
+				hours(s.LeadTime.P50), hours(s.LeadTime.P85), hours(s.LeadTime.P95),
+				hours(s.CycleTime.P50), hours(s.CycleTime.P85), hours(s.CycleTime.P95)}
+			for _, status := range statuses {
+				p := s.TimeInStatus[status.Id]
+				record = append(record, hours(p.P50), hours(p.P85), hours(p.P95))
+			}
+			w.Write(record)
+		}
+		return
+	}
+
+	names, err := c.IssueNames(currentProject())
+	if err != nil {
+		fatal("Failed to get names: %s\n", err)
+	}
+	assigneeSet := map[int]bool{}
+	for _, m := range metrics {
+		for id := range m.AssigneeDurations {
+			assigneeSet[id] = true
+		}
+	}
+	var assignees []int
+	for id := range assigneeSet {
+		assignees = append(assignees, id)
+	}
+	sort.Ints(assignees)
+	header := []string{"id", "project", "tracker", "created_on", "closed_on", "lead_time_h", "cycle_time_h"}
+	for _, s := range statuses {
+		header = append(header, s.Name+"_h")
+	}
+	for _, id := range assignees {
+		name := names.Users[id]
+		if id == 0 {
+			name = "unassigned"
+		} else if name == "" {
+			name = strconv.Itoa(id)
+		}
+		header = append(header, "assigned_to:"+name+"_h")
+	}
+	w.Write(header)
+	for _, m := range metrics {
+		closedOn := ""
+		if !m.ClosedOn.IsZero() {
+			closedOn = m.ClosedOn.Format(time.RFC3339)
+		}
+		tracker := ""
+		if m.Tracker != nil {
+			tracker = m.Tracker.Name
+		}
+		project := ""
+		if m.Project != nil {
+			project = m.Project.Name
+		}
+		record := []string{strconv.Itoa(m.IssueId), project, tracker,
+			m.CreatedOn.Format(time.RFC3339), closedOn, hours(m.LeadTime), hours(m.CycleTime)}
+		for _, s := range statuses {
+			record = append(record, hours(m.StatusDurations[s.Id]))
+		}
+		for _, id := range assignees {
+			record = append(record, hours(m.AssigneeDurations[id]))
+		}
+		w.Write(record)
+	}
+}
+
+func exportIssues(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", "csv", "output format: csv, jsonl or md")
+	columns := fs.String("columns", "", "comma separated columns, cf:<name> for custom fields")
+	journals := fs.Bool("journals", false, "include journals")
+	fs.Parse(args)
+
+	opts := &redmine.ExportOptions{IncludeJournals: *journals}
+	if *columns != "" {
+		opts.Columns = strings.Split(*columns, ",")
+	}
+	filter := &redmine.IssueFilter{
+		ProjectId:    fmt.Sprint(currentProject()),
+		ExtraFilters: map[string]string{},
+	}
+	for _, column := range opts.Columns {
+		if column == "relations" {
+			filter.ExtraFilters["include"] = "relations"
+		}
+	}
+
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	var issues []redmine.Issue
+	var err error
+	if *journals {
+		issues, err = c.IssuesWithJournals(filter)
+	} else {
+		issues, err = c.IssuesByFilter(filter)
+	}
+	if err != nil {
+		fatal("Failed to list issues: %s\n", err)
+	}
+
+	switch *format {
+	case "csv":
+		err = redmine.ExportIssuesCSV(os.Stdout, issues, opts)
+	case "jsonl":
+		err = redmine.ExportIssuesJSONL(os.Stdout, issues, opts)
+	case "md", "markdown":
+		err = redmine.ExportIssuesMarkdown(os.Stdout, issues, opts)
+	default:
+		err = errors.New("unknown format " + *format)
+	}
+	if err != nil {
+		fatal("Failed to export issues: %s\n", err)
+	}
+}
+
+func importIssues(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	mapping := fs.String("map", "", "comma separated column=field pairs, e.g. Title=subject,Type=tracker")
+	reportFile := fs.String("report", "", "JSON file recording imported rows, read and updated")
+	dryRun := fs.Bool("dry-run", false, "validate only, create nothing")
+	fs.Parse(args)
+	if fs.NArg() != 1 || *mapping == "" {
+		usage()
+	}
+
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fatal("Failed to open file: %s\n", err)
+	}
+	defer f.Close()
+	var rows []redmine.ImportRow
+	if strings.HasSuffix(fs.Arg(0), ".jsonl") || strings.HasSuffix(fs.Arg(0), ".json") {
+		rows, err = redmine.ReadImportJSONL(f)
+	} else {
+		rows, err = redmine.ReadImportCSV(f)
+	}
+	if err != nil {
+		fatal("Failed to read file: %s\n", err)
+	}
+
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	names, err := c.IssueNames(currentProject())
+	if err != nil {
+		fatal("Failed to get names: %s\n", err)
+	}
+	// Custom field definitions are only visible to administrators.
+	if cfs, err := c.CustomFields(); err == nil {
+		names.CustomFields = map[int]string{}
+		for _, cf := range cfs {
+			names.CustomFields[cf.Id] = cf.Name
+		}
+	}
+
+	im := &redmine.Importer{
+		Client:    c,
+		ProjectId: currentProject(),
+		Mapping:   map[string]string{},
+		Names:     names,
+		DryRun:    *dryRun,
+		Report:    &redmine.ImportReport{},
+	}
+	for _, pair := range strings.Split(*mapping, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			fatal("Invalid mapping: %s\n", errors.New(pair))
+		}
+		im.Mapping[kv[0]] = kv[1]
+	}
+	if *reportFile != "" {
+		if b, err := ioutil.ReadFile(*reportFile); err == nil {
+			if err := json.Unmarshal(b, im.Report); err != nil {
+				fatal("Failed to read report: %s\n", err)
+			}
+		}
+	}
+
+	report, importErr := im.Import(rows)
+	if report != nil && *reportFile != "" && !*dryRun {
+		b, err := json.MarshalIndent(report, "", "\t")
+		if err != nil {
+			fatal("Failed to marshal report: %s\n", err)
+		}
+		if err := ioutil.WriteFile(*reportFile, b, 0644); err != nil {
+			fatal("Failed to write report: %s\n", err)
+		}
+	}
+	if importErr != nil {
+		fatal("Failed to import issues:\n%s\n", importErr)
+	}
+	keys := make([]string, 0, len(report.Issues))
+	for k := range report.Issues {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("%s: %d\n", k, report.Issues[k])
+	}
+}
+
+func bulkIssues(args []string) {
+	fs := flag.NewFlagSet("bulk", flag.ExitOnError)
+	filterArg := fs.String("filter", "", "comma separated key=value issue filters")
+	queryArg := fs.String("query", "", "saved query name")
+	setArg := fs.String("set", "", "comma separated field=value pairs, e.g. status=Closed,fixed_version=2.0")
+	notes := fs.String("notes", "", "notes added to every issue")
+	dryRun := fs.Bool("dry-run", false, "list selected issues, change nothing")
+	jobs := fs.Int("jobs", 4, "number of concurrent requests")
+	fs.Parse(args)
+	if *setArg == "" && *notes == "" {
+		usage()
+	}
+
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	var selector redmine.IssueSelector
+	if *queryArg != "" {
+		query, err := c.QueryByName(*queryArg)
+		if err != nil {
+			fatal("Failed to find query: %s\n", err)
+		}
+		selector.Filter = queryFilter(query)
+	} else {
+		filter := &redmine.IssueFilter{
+			ProjectId:    fmt.Sprint(currentProject()),
+			ExtraFilters: map[string]string{},
+		}
+		if *filterArg != "" {
+			for _, pair := range strings.Split(*filterArg, ",") {
+				kv := strings.SplitN(pair, "=", 2)
+				if len(kv) != 2 {
+					fatal("Invalid filter: %s\n", errors.New(pair))
+				}
+				filter.ExtraFilters[kv[0]] = url.QueryEscape(kv[1])
+			}
+		}
+		selector.Filter = filter
+	}
+
+	patch := redmine.IssuePatch{}
+	if *setArg != "" {
+		names, err := c.IssueNames(currentProject())
+		if err != nil {
+			fatal("Failed to get names: %s\n", err)
+		}
+		for _, pair := range strings.Split(*setArg, ",") {
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				fatal("Invalid field: %s\n", errors.New(pair))
+			}
+			switch kv[0] {
+			case "project", "tracker", "status", "priority", "assigned_to", "category", "fixed_version":
+				id, ok := names.Id(kv[0]+"_id", kv[1])
+				if !ok {
+					fatal("Unknown %s\n", errors.New(kv[0]+" "+kv[1]))
+				}
+				patch[kv[0]+"_id"] = id
+			default:
+				patch[kv[0]] = kv[1]
+			}
+		}
+	}
+
+	results, err := c.BulkUpdate(selector, patch, &redmine.BulkOptions{
+		Concurrency: *jobs,
+		Notes:       *notes,
+		DryRun:      *dryRun,
+	})
+	if err != nil {
+		fatal("Failed to select issues: %s\n", err)
+	}
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Printf("%4d: %s\n", r.IssueId, r.Err)
+		} else if *dryRun {
+			fmt.Printf("%4d: would update\n", r.IssueId)
+		} else {
+			fmt.Printf("%4d: ok\n", r.IssueId)
+		}
+	}
+	if failed > 0 {
+		fatal(fmt.Sprintf("%d of %d issues failed\n", failed, len(results)), nil)
+	}
+}
+
+func listIssuesByQuery(name string) {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	query, err := c.QueryByName(name)
+	if err != nil {
+		fatal("Failed to find query: %s\n", err)
+	}
+	listIssues(queryFilter(query))
+}
+
+// queryFilter returns a filter for the issues of a saved query, scoped to
+// the project of the query as Redmine ignores project queries otherwise.
+func queryFilter(query *redmine.Query) *redmine.IssueFilter {
+	filter := &redmine.IssueFilter{
+		ExtraFilters: map[string]string{"query_id": strconv.Itoa(query.Id)},
+	}
+	if query.ProjectId != 0 {
+		filter.ProjectId = strconv.Itoa(query.ProjectId)
+	}
+	return filter
+}
+
 func addProject(name, identifier, description string) {
 	var project redmine.Project
 	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
@@ -464,6 +892,88 @@ UpdatedOn: %s
 		project.Description)
 }
 
+// confirm asks a yes/no question on the terminal.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func changeProjectStatus(action string, args []string) {
+	fs := flag.NewFlagSet("project "+action, flag.ExitOnError)
+	yes := fs.Bool("y", false, "do not ask for confirmation")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+	id, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		fatal("Invalid project id: %s\n", err)
+	}
+
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	if !*yes {
+		question := fmt.Sprintf("%s project %d", strings.ToUpper(action[:1])+action[1:], id)
+		if action == "unarchive" {
+			// Archived projects are not found by id.
+			filter := redmine.NewProjectsFilter()
+			filter.Status(redmine.ProjectStatusArchived)
+			projects, _ := c.ProjectsWithFilter(filter)
+			for _, p := range projects {
+				if p.Id == id {
+					question += ": " + p.Name
+				}
+			}
+		} else {
+			project, err := c.Project(id)
+			if err != nil {
+				fatal("Failed to show project: %s\n", err)
+			}
+			question += ": " + project.Name
+		}
+		if !confirm(question + "?") {
+			fatal("Canceled\n", nil)
+		}
+	}
+	switch action {
+	case "archive":
+		err = c.ArchiveProject(id)
+	case "unarchive":
+		err = c.UnarchiveProject(id)
+	case "close":
+		err = c.CloseProject(id)
+	case "reopen":
+		err = c.ReopenProject(id)
+	}
+	if err != nil {
+		fatal("Failed to "+action+" project: %s\n", err)
+	}
+}
+
+func treeProjects() {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	tree, err := c.ProjectTree()
+	if err != nil {
+		fatal("Failed to get project tree: %s\n", err)
+	}
+	tree.Walk(func(node *redmine.ProjectNode, depth int) {
+		status := ""
+		switch node.Project.Status {
+		case redmine.ProjectStatusClosed:
+			status = " [closed]"
+		case redmine.ProjectStatusArchived:
+			status = " [archived]"
+		}
+		fmt.Printf("%s%4d: %s (%s)%s\n",
+			strings.Repeat("  ", depth),
+			node.Project.Id,
+			node.Project.Name,
+			node.Project.Identifier,
+			status)
+	})
+}
+
 func listProjects() {
 	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
 	issues, err := c.Projects()
@@ -544,6 +1054,75 @@ func listUsers() {
 	}
 }
 
+func listGroups() {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	groups, err := c.Groups()
+	if err != nil {
+		fatal("Failed to list groups: %s\n", err)
+	}
+	for _, g := range groups {
+		fmt.Printf("%4d: %s\n", g.Id, g.Name)
+	}
+}
+
+func showGroup(id int) {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	group, err := c.Group(id, redmine.GroupIncludeUsers, redmine.GroupIncludeMemberships)
+	if err != nil {
+		fatal("Failed to show group: %s\n", err)
+	}
+	fmt.Printf("Id: %d\nName: %s\n", group.Id, group.Name)
+	fmt.Println("\nUsers:")
+	for _, u := range group.Users {
+		fmt.Printf("%4d: %s\n", u.Id, u.Name)
+	}
+	fmt.Println("\nProjects:")
+	for _, m := range group.Memberships {
+		roles := make([]string, len(m.Roles))
+		for i, r := range m.Roles {
+			roles[i] = r.Name
+		}
+		fmt.Printf("%4d: %s (%s)\n", m.Project.Id, m.Project.Name, strings.Join(roles, ", "))
+	}
+}
+
+func createGroup(name string) {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	group, err := c.CreateGroup(redmine.Group{Name: name})
+	if err != nil {
+		fatal("Failed to create group: %s\n", err)
+	}
+	fmt.Printf("%4d: %s\n", group.Id, group.Name)
+}
+
+func deleteGroup(id int) {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	if err := c.DeleteGroup(id); err != nil {
+		fatal("Failed to delete group: %s\n", err)
+	}
+}
+
+// changeGroupUsers adds the users, given by id or login, to the group, or
+// removes them.
+func changeGroupUsers(id int, users []string, remove bool) {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	resolver := redmine.NewResolver(c)
+	for _, login := range users {
+		userId, err := resolver.UserId(login)
+		if err != nil {
+			fatal("Failed to find user: %s\n", err)
+		}
+		if remove {
+			err = c.RemoveUserFromGroup(id, userId)
+		} else {
+			err = c.AddUserToGroup(id, userId)
+		}
+		if err != nil {
+			fatal("Failed to change group users: %s\n", err)
+		}
+	}
+}
+
 func showNews(id int) {
 	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
 	news, err := c.News(id)
@@ -580,7 +1159,7 @@ CreatedOn: %s
 
 func listNews() {
 	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
-	news, err := c.News(conf.Project)
+	news, err := c.News(currentProject())
 	if err != nil {
 		fatal("Failed to list users: %s\n", err)
 	}
@@ -625,9 +1204,69 @@ func listVersions(projectId int) {
 	}
 }
 
+func sparkline(values []float64) string {
+	ticks := []rune("▁▂▃▄▅▆▇█")
+	max := 0.0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+	line := make([]rune, len(values))
+	for i, v := range values {
+		t := 0
+		if max > 0 {
+			t = int(v / max * float64(len(ticks)-1))
+		}
+		line[i] = ticks[t]
+	}
+	return string(line)
+}
+
+func burndownVersion(id int, format string) {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	report, err := c.VersionReport(id, nil)
+	if err != nil {
+		fatal("Failed to build version report: %s\n", err)
+	}
+	switch format {
+	case "csv":
+		statuses, err := c.IssueStatuses()
+		if err != nil {
+			fatal("Failed to get issue statuses: %s\n", err)
+		}
+		names := map[int]string{}
+		for _, s := range statuses {
+			names[s.Id] = s.Name
+		}
+		if err := report.WriteCSV(os.Stdout, names); err != nil {
+			fatal("Failed to write report: %s\n", err)
+		}
+	case "json":
+		b, err := json.MarshalIndent(report, "", "\t")
+		if err != nil {
+			fatal("Failed to marshal report: %s\n", err)
+		}
+		fmt.Println(string(b))
+	default:
+		if len(report.Days) == 0 {
+			return
+		}
+		var remaining, open []float64
+		for _, d := range report.Days {
+			remaining = append(remaining, d.RemainingHours)
+			open = append(open, float64(d.Open))
+		}
+		first, last := report.Days[0], report.Days[len(report.Days)-1]
+		fmt.Printf("%s .. %s\n", first.Date, last.Date)
+		fmt.Printf("Remaining: %s %.1fh\n", sparkline(remaining), last.RemainingHours)
+		fmt.Printf("Open:      %s %d\n", sparkline(open), last.Open)
+	}
+}
+
 func showWikiPage(title string) {
 	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
-	page, err := c.WikiPage(conf.Project, title)
+	page, err := c.WikiPage(currentProject(), title)
 	if err != nil {
 		fatal("Failed to show user: %s\n", err)
 	}
@@ -653,7 +1292,7 @@ Comments: %s
 
 func listWikiPages() {
 	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
-	pages, err := c.WikiPages(conf.Project)
+	pages, err := c.WikiPages(currentProject())
 	if err != nil {
 		fatal("Failed to list wiki pages: %s\n", err)
 	}
@@ -664,7 +1303,7 @@ func listWikiPages() {
 
 func editWikiPage(title string) error {
 	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
-	page, err := c.WikiPage(conf.Project, title)
+	page, err := c.WikiPage(currentProject(), title)
 	if err != nil {
 		if err.Error() != "Not Found" {
 			return fmt.Errorf("Failed to read wiki page for editing: %s\n", err)
@@ -700,17 +1339,196 @@ func editWikiPage(title string) error {
 	}
 	page.Text = text
 	if page.Version == nil {
-		if _, err := c.CreateWikiPage(conf.Project, *page); err != nil {
+		if _, err := c.CreateWikiPage(currentProject(), *page); err != nil {
 			return err
 		}
 	} else {
-		if err := c.UpdateWikiPage(conf.Project, *page); err != nil {
+		if err := c.UpdateWikiPage(currentProject(), *page); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+func getAttachment(id int, path string) {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	if path == "" {
+		attachment, err := c.Attachment(id)
+		if err != nil {
+			fatal("Failed to get attachment: %s\n", err)
+		}
+		path = filepath.Base(attachment.Filename)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fatal("Failed to create file: %s\n", err)
+	}
+	defer f.Close()
+	err = c.DownloadAttachment(id, f)
+	if err != nil {
+		os.Remove(path)
+		fatal("Failed to download attachment: %s\n", err)
+	}
+}
+
+func deleteAttachment(id int) {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	err := c.DeleteAttachment(id)
+	if err != nil {
+		fatal("Failed to delete attachment: %s\n", err)
+	}
+}
+
+func listFiles() {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	files, err := c.ProjectFiles(currentProject())
+	if err != nil {
+		fatal("Failed to list files: %s\n", err)
+	}
+	for _, f := range files {
+		version := ""
+		if f.Version != nil {
+			version = f.Version.Name
+		}
+		fmt.Printf("%4d: %s %s\n", f.Id, f.Filename, version)
+	}
+}
+
+func publishFiles(version string, paths []string) {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	versionId, err := strconv.Atoi(version)
+	if err != nil {
+		versions, err := c.Versions(currentProject())
+		if err != nil {
+			fatal("Failed to list versions: %s\n", err)
+		}
+		for _, v := range versions {
+			if v.Name == version {
+				versionId = v.Id
+				break
+			}
+		}
+		if versionId == 0 {
+			fatal("Failed to find version: %s\n", errors.New(version))
+		}
+	}
+	for _, path := range paths {
+		upload, err := c.Upload(path)
+		if err != nil {
+			fatal("Failed to upload file: %s\n", err)
+		}
+		err = c.AddProjectFile(currentProject(), upload, versionId, "")
+		if err != nil {
+			fatal("Failed to publish file: %s\n", err)
+		}
+		fmt.Println(upload.Filename)
+	}
+}
+
+func search(args []string) {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	types := fs.String("type", "", "comma separated result types (issues,news,wiki_pages,...)")
+	titlesOnly := fs.Bool("titles-only", false, "search in titles only")
+	openIssues := fs.Bool("open", false, "search in open issues only")
+	project := fs.Bool("project", false, "search in the default project only")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		usage()
+	}
+
+	opts := &redmine.SearchOptions{
+		TitlesOnly: *titlesOnly,
+		OpenIssues: *openIssues,
+	}
+	if *types != "" {
+		opts.Types = strings.Split(*types, ",")
+	}
+	if *project {
+		opts.ProjectId = currentProject()
+	}
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	results, err := c.Search(strings.Join(fs.Args(), " "), opts)
+	if err != nil {
+		fatal("Failed to search: %s\n", err)
+	}
+	for _, r := range results.Results {
+		fmt.Printf("%s: %s\n    %s\n", r.Type, r.Title, r.Url)
+	}
+}
+
+func listCustomFields() {
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	definitions, err := c.CustomFields()
+	if err != nil {
+		fatal("Failed to list custom fields: %s\n", err)
+	}
+	b, err := json.MarshalIndent(definitions, "", "  ")
+	if err != nil {
+		fatal("Failed to encode custom fields: %s\n", err)
+	}
+	fmt.Println(string(b))
+}
+
+func applyCustomFields(args []string) {
+	fs := flag.NewFlagSet("cf apply", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "only show the changes")
+	prune := fs.Bool("prune", false, "delete custom fields missing from the file")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		usage()
+	}
+
+	b, err := ioutil.ReadFile(fs.Arg(0))
+	if err != nil {
+		fatal("Failed to read file: %s\n", err)
+	}
+	var definitions []redmine.CustomFieldDefinition
+	if err := json.Unmarshal(b, &definitions); err != nil {
+		var wrapped struct {
+			CustomFields []redmine.CustomFieldDefinition `json:"custom_fields"`
+		}
+		if json.Unmarshal(b, &wrapped) != nil {
+			fatal("Failed to parse file: %s\n", err)
+		}
+		definitions = wrapped.CustomFields
+	}
+
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	changes, err := c.ApplyCustomFields(definitions, &redmine.ApplyOptions{DryRun: *dryRun, Prune: *prune})
+	for _, ch := range changes {
+		fmt.Println(ch)
+	}
+	if err != nil {
+		fatal("Failed to apply custom fields: %s\n", err)
+	}
+}
+
+func genCustomFields(args []string) {
+	fs := flag.NewFlagSet("gen customfields", flag.ExitOnError)
+	pkg := fs.String("package", "main", "package name of the generated code")
+	output := fs.String("o", "", "output file (default stdout)")
+	fs.Parse(args)
+
+	c := redmine.NewClient(conf.Endpoint, conf.Apikey)
+	definitions, err := c.CustomFields()
+	if err != nil {
+		fatal("Failed to list custom fields: %s\n", err)
+	}
+	out := os.Stdout
+	if *output != "" {
+		out, err = os.Create(*output)
+		if err != nil {
+			fatal("Failed to create file: %s\n", err)
+		}
+	}
+	if err := gen.CustomFields(out, *pkg, definitions); err != nil {
+		fatal("Failed to generate code: %s\n", err)
+	}
+	if err := out.Close(); err != nil {
+		fatal("Failed to write file: %s\n", err)
+	}
+}
+
 func initConfigFile(endpoint string, apikey string, project string) {
 	u, err := url.Parse(endpoint)
 	if err != nil {
@@ -721,9 +1539,9 @@ func initConfigFile(endpoint string, apikey string, project string) {
 	if m, _ := regexp.MatchString("^[[:alnum:]]+$", apikey); !m {
 		fatal("%s\n", errors.New("apikey must be [0-9a-f] only"))
 	}
-	projectId, err := strconv.Atoi(project)
-	if err != nil {
-		fatal("Project id can not convert to integer: %s\n", err)
+	var projectRef interface{} = project
+	if projectId, err := strconv.Atoi(project); err == nil {
+		projectRef = projectId
 	}
 
 	filename := createConfigFileName()
@@ -739,7 +1557,7 @@ func initConfigFile(endpoint string, apikey string, project string) {
 	c := config{}
 	c.Endpoint = endpoint
 	c.Apikey = apikey
-	c.Project = projectId
+	c.ProjectRef = projectRef
 
 	bytes, err := json.MarshalIndent(c, "", "\t")
 	if err != nil {
@@ -827,9 +1645,20 @@ Project Commands:
   delete   d delete given project.
              $ godmine p d 1
 
+  archive    archive given project, or unarchive it, after confirmation.
+             $ godmine p archive 1
+             $ godmine p unarchive -y 1
+
+  close      close given project, or reopen it, after confirmation.
+             $ godmine p close 1
+             $ godmine p reopen -y 1
+
   list     l listing projects.
              $ godmine p l
 
+  tree     t show projects with their subprojects.
+             $ godmine p t
+
 Issue Commands:
   add      a create issue with text editor.
              $ godmine i a
@@ -852,15 +1681,35 @@ Issue Commands:
   notes    n add notes to given issue.
              $ godmine i n 1
 
-  list     l listing issues.
+  tree     t show given issue with its subtasks.
+             $ godmine i t 1
+
+  bulk       update many issues at once.
+             $ godmine i bulk --filter status_id=open,fixed_version_id=3 --set fixed_version=2.0
+             $ godmine i bulk --query "Open bugs" --set status=Closed --notes "Closed in bulk"
+
+  export     export project's issues as csv, jsonl or md.
+             $ godmine i export --format csv --columns id,status,assigned_to,cf:Severity
+
+  import     create issues from a csv or jsonl file.
+             $ godmine i import --map Title=subject,Type=tracker,Id=key --report report.json backlog.csv
+             $ godmine i import --dry-run --map Title=subject backlog.jsonl
+
+  metrics    output time-in-status, time-per-assignee, lead and cycle times as CSV.
+             $ godmine i metrics
+             $ godmine i metrics --in-progress "In Progress" --summary tracker
+
+  list     l listing issues, optionally by saved query name.
              $ godmine i l
+             $ godmine i l --query "Open bugs"
 
 Membership Commands:
   show     s show given membership.
              $ godmine m s 1
 
-  list     l listing memberships of given project.
+  list     l listing memberships of given project, by id or identifier.
              $ godmine m l 1
+             $ godmine m l website
 
 User Commands:
   show     s show given user.
@@ -869,12 +1718,36 @@ User Commands:
   list     l listing users.
              $ godmine u l
 
+Group Commands:
+  create   c create group with given name.
+             $ godmine g c developers
+
+  show     s show given group with its users and projects.
+             $ godmine g s 1
+
+  delete   d delete given group.
+             $ godmine g d 1
+
+  add        add users, given by id or login, to given group.
+             $ godmine g add 1 alice 42
+
+  rm         remove users, given by id or login, from given group.
+             $ godmine g rm 1 alice
+
+  list     l listing groups.
+             $ godmine g l
+
 Version Commands:
   show     s show given version.
              $ godmine v s 1
 
-  list     s listing versions of given project.
+  list     l listing versions of given project, by id or identifier.
              $ godmine v l 1
+             $ godmine v l website
+
+  burndown b show burndown of given version, or output it as csv or json.
+             $ godmine v b 1
+             $ godmine v b 1 csv
 
 Wiki Commands:
   show     s show wiki page griven by title.
@@ -886,8 +1759,41 @@ Wiki Commands:
   edit     e edit wiki page griven by title with editor
              $ godmine w e home
 
+Attachment Commands:
+  get      g download given attachment, optionally to given path.
+             $ godmine a g 1
+             $ godmine a g 1 out.zip
+
+  rm       r delete given attachment.
+             $ godmine a r 1
+
+File Commands:
+  list     l listing project's files.
+             $ godmine f l
+
+  publish  p publish files for given version id or name.
+             $ godmine f p 1.0.0 dist/app.tar.gz dist/app.zip
+
+Search Commands:
+  search   s search issues, wiki pages, news and more.
+             $ godmine s "some words"
+             $ godmine s --type issues,wiki_pages --titles-only --open --project words
+
+Custom Field Commands:
+  list     l show custom field definitions as JSON.
+             $ godmine cf l > fields.json
+
+  apply      create, update and, with --prune, delete custom fields to
+             match given JSON file.
+             $ godmine cf apply --dry-run fields.json
+
+Generate Commands:
+  customfields
+             generate typed Go code for the custom fields.
+             $ godmine gen customfields -package main -o customfields_gen.go
+
 Config Commands:
-  init     i initialize configuration file.
+  init     i initialize configuration file. project is an id or identifier.
              $ godmine c i endpoint apikey project
 
   edit     e edit configuration file with editor
@@ -1010,6 +1916,29 @@ func main() {
 				usage()
 			}
 			break
+		case "t", "tree":
+			if flag.NArg() == 3 {
+				id, err := strconv.Atoi(flag.Arg(2))
+				if err != nil {
+					fatal("Invalid issue id: %s\n", err)
+				}
+				treeIssue(id)
+			} else {
+				usage()
+			}
+			break
+		case "bulk":
+			bulkIssues(flag.Args()[2:])
+			break
+		case "export":
+			exportIssues(flag.Args()[2:])
+			break
+		case "import":
+			importIssues(flag.Args()[2:])
+			break
+		case "metrics":
+			metricsIssues(flag.Args()[2:])
+			break
 		case "x", "close":
 			if flag.NArg() == 3 {
 				id, err := strconv.Atoi(flag.Arg(2))
@@ -1022,11 +1951,17 @@ func main() {
 			}
 			break
 		case "l", "list":
-			listIssues(nil)
+			if flag.NArg() == 4 && (flag.Arg(2) == "-q" || flag.Arg(2) == "--query") {
+				listIssuesByQuery(flag.Arg(3))
+			} else if flag.NArg() == 2 {
+				listIssues(nil)
+			} else {
+				usage()
+			}
 			break
 		case "p", "project":
 			filter := &redmine.IssueFilter{
-				ProjectId: fmt.Sprint(conf.Project),
+				ProjectId: fmt.Sprint(currentProject()),
 			}
 			listIssues(filter)
 			break
@@ -1062,6 +1997,12 @@ func main() {
 				usage()
 			}
 			break
+		case "t", "tree":
+			treeProjects()
+			break
+		case "archive", "unarchive", "close", "reopen":
+			changeProjectStatus(flag.Arg(1), flag.Args()[2:])
+			break
 		case "d", "delete":
 			if flag.NArg() == 3 {
 				id, err := strconv.Atoi(flag.Arg(2))
@@ -1094,11 +2035,7 @@ func main() {
 			break
 		case "l", "list":
 			if flag.NArg() == 3 {
-				projectId, err := strconv.Atoi(flag.Arg(2))
-				if err != nil {
-					fatal("Invalid project id: %s\n", err)
-				}
-				listMemberships(projectId)
+				listMemberships(projectArg(flag.Arg(2)))
 			} else {
 				usage()
 			}
@@ -1125,6 +2062,54 @@ func main() {
 		default:
 			usage()
 		}
+	case "g", "group":
+		switch flag.Arg(1) {
+		case "s", "show":
+			if flag.NArg() == 3 {
+				id, err := strconv.Atoi(flag.Arg(2))
+				if err != nil {
+					fatal("Invalid group id: %s\n", err)
+				}
+				showGroup(id)
+			} else {
+				usage()
+			}
+			break
+		case "d", "delete":
+			if flag.NArg() == 3 {
+				id, err := strconv.Atoi(flag.Arg(2))
+				if err != nil {
+					fatal("Invalid group id: %s\n", err)
+				}
+				deleteGroup(id)
+			} else {
+				usage()
+			}
+			break
+		case "c", "create":
+			if flag.NArg() == 3 {
+				createGroup(flag.Arg(2))
+			} else {
+				usage()
+			}
+			break
+		case "add", "rm":
+			if flag.NArg() >= 4 {
+				id, err := strconv.Atoi(flag.Arg(2))
+				if err != nil {
+					fatal("Invalid group id: %s\n", err)
+				}
+				changeGroupUsers(id, flag.Args()[3:], flag.Arg(1) == "rm")
+			} else {
+				usage()
+			}
+			break
+		case "l", "list":
+			listGroups()
+			break
+		default:
+			usage()
+		}
 	case "n", "news":
 		switch flag.Arg(1) {
 		case "s", "show":
@@ -1158,11 +2143,17 @@ func main() {
 			}
 		case "l", "list":
 			if flag.NArg() == 3 {
-				projectId, err := strconv.Atoi(flag.Arg(2))
+				listVersions(projectArg(flag.Arg(2)))
+			} else {
+				usage()
+			}
+		case "b", "burndown":
+			if flag.NArg() == 3 || flag.NArg() == 4 {
+				id, err := strconv.Atoi(flag.Arg(2))
 				if err != nil {
-					fatal("Invalid project id: %s\n", err)
+					fatal("Invalid version id: %s\n", err)
 				}
-				listVersions(projectId)
+				burndownVersion(id, flag.Arg(3))
 			} else {
 				usage()
 			}
@@ -1201,6 +2192,69 @@ func main() {
 			usage()
 
 		}
+	case "a", "attach":
+		switch flag.Arg(1) {
+		case "g", "get":
+			if flag.NArg() == 3 || flag.NArg() == 4 {
+				id, err := strconv.Atoi(flag.Arg(2))
+				if err != nil {
+					fatal("Invalid attachment id: %s\n", err)
+				}
+				getAttachment(id, flag.Arg(3))
+			} else {
+				usage()
+			}
+			break
+		case "r", "rm":
+			if flag.NArg() == 3 {
+				id, err := strconv.Atoi(flag.Arg(2))
+				if err != nil {
+					fatal("Invalid attachment id: %s\n", err)
+				}
+				deleteAttachment(id)
+			} else {
+				usage()
+			}
+			break
+		default:
+			usage()
+		}
+	case "f", "files":
+		switch flag.Arg(1) {
+		case "l", "list":
+			listFiles()
+			break
+		case "p", "publish":
+			if flag.NArg() >= 4 {
+				publishFiles(flag.Arg(2), flag.Args()[3:])
+			} else {
+				usage()
+			}
+			break
+		default:
+			usage()
+		}
+	case "s", "search":
+		search(flag.Args()[1:])
+	case "cf", "customfield":
+		switch flag.Arg(1) {
+		case "l", "list":
+			listCustomFields()
+			break
+		case "apply":
+			applyCustomFields(flag.Args()[2:])
+			break
+		default:
+			usage()
+		}
+	case "gen":
+		switch flag.Arg(1) {
+		case "customfields":
+			genCustomFields(flag.Args()[2:])
+			break
+		default:
+			usage()
+		}
 	default:
 		usage()
 	}
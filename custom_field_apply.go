@@ -0,0 +1,272 @@
+package redmine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CustomFieldChange is a change needed to bring the custom fields of the
+// server to the desired state. Action is "create", "update" or "delete";
+// Fields lists the attributes changed by an update.
+type CustomFieldChange struct {
+	Action     string
+	Definition CustomFieldDefinition
+	Fields     []string
+}
+
+func (ch CustomFieldChange) String() string {
+	s := fmt.Sprintf("%s %s custom field %q", ch.Action, ch.Definition.CustomizedType, ch.Definition.Name)
+	if len(ch.Fields) > 0 {
+		s += " (" + strings.Join(ch.Fields, ", ") + ")"
+	}
+	return s
+}
+
+// ApplyOptions controls ApplyCustomFields.
+type ApplyOptions struct {
+	// DryRun computes the changes without applying them.
+	DryRun bool
+	// Prune deletes the custom fields of the server not in the desired
+	// state.
+	Prune bool
+}
+
+func idSet(ids []int, names []IdName) []int {
+	set := append([]int(nil), ids...)
+	for _, n := range names {
+		set = append(set, n.Id)
+	}
+	sort.Ints(set)
+	out := set[:0]
+	for i, id := range set {
+		if i == 0 || id != set[i-1] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func sameInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func possibleValues(d *CustomFieldDefinition) []string {
+	values := make([]string, len(d.PossibleValues))
+	for i, pv := range d.PossibleValues {
+		values[i] = pv.Value
+	}
+	return values
+}
+
+// diffCustomField returns the attributes of have that differ from want.
+// Attributes not set in want, such as keys missing from the JSON it was
+// decoded from or nil pointers, are left alone.
+func diffCustomField(want, have *CustomFieldDefinition) []string {
+	var fields []string
+	check := func(name string, changed bool) {
+		if changed {
+			fields = append(fields, name)
+		}
+	}
+	intPtr := func(a, b *int) bool {
+		return a != nil && (b == nil || *a != *b)
+	}
+	check("field_format", want.has("field_format") && want.FieldFormat != have.FieldFormat)
+	check("name", want.Name != have.Name)
+	check("description", want.Description != nil && (have.Description == nil || *want.Description != *have.Description))
+	check("regexp", want.has("regexp") && want.Regexp != have.Regexp)
+	check("min_length", intPtr(want.MinLength, have.MinLength))
+	check("max_length", intPtr(want.MaxLength, have.MaxLength))
+	check("is_required", want.has("is_required") && want.IsRequired != have.IsRequired)
+	check("is_filter", want.has("is_filter") && want.IsFilter != have.IsFilter)
+	check("searchable", want.has("searchable") && want.Searchable != have.Searchable)
+	check("multiple", want.has("multiple") && want.Multiple != have.Multiple)
+	check("default_value", want.DefaultValue != nil && fmt.Sprint(want.DefaultValue) != fmt.Sprint(have.DefaultValue))
+	check("visible", want.has("visible") && want.Visible != have.Visible)
+	check("possible_values", want.has("possible_values") && strings.Join(possibleValues(want), "\n") != strings.Join(possibleValues(have), "\n"))
+	check("trackers", want.has("trackers", "tracker_ids") && !sameInts(idSet(want.TrackerIds, want.Trackers), idSet(nil, have.Trackers)))
+	check("roles", want.has("roles", "role_ids") && !sameInts(idSet(want.RoleIds, want.Roles), idSet(nil, have.Roles)))
+	check("edit_tag_style", want.EditTagStyle != nil && (have.EditTagStyle == nil || *want.EditTagStyle != *have.EditTagStyle))
+	return fields
+}
+
+// mergeCustomField returns have with the changed attributes of want.
+func mergeCustomField(want, have *CustomFieldDefinition, fields []string) CustomFieldDefinition {
+	merged := *have
+	merged.given = nil
+	for _, field := range fields {
+		switch field {
+		case "name":
+			merged.Name = want.Name
+		case "description":
+			merged.Description = want.Description
+		case "regexp":
+			merged.Regexp = want.Regexp
+		case "min_length":
+			merged.MinLength = want.MinLength
+		case "max_length":
+			merged.MaxLength = want.MaxLength
+		case "is_required":
+			merged.IsRequired = want.IsRequired
+		case "is_filter":
+			merged.IsFilter = want.IsFilter
+		case "searchable":
+			merged.Searchable = want.Searchable
+		case "multiple":
+			merged.Multiple = want.Multiple
+		case "default_value":
+			merged.DefaultValue = want.DefaultValue
+		case "visible":
+			merged.Visible = want.Visible
+		case "possible_values":
+			merged.PossibleValues = want.PossibleValues
+		case "trackers":
+			merged.Trackers = want.Trackers
+			merged.TrackerIds = append([]int{}, want.TrackerIds...)
+		case "roles":
+			merged.Roles = want.Roles
+			merged.RoleIds = append([]int{}, want.RoleIds...)
+		case "edit_tag_style":
+			merged.EditTagStyle = want.EditTagStyle
+		}
+	}
+	return merged
+}
+
+// resolveIds sets TrackerIds and RoleIds of the definition from its
+// Trackers and Roles, which may be given by name only.
+func (d *CustomFieldDefinition) resolveIds(trackers, roles map[int]string) error {
+	resolve := func(names []IdName, m map[int]string, what string) ([]IdName, error) {
+		resolved := make([]IdName, len(names))
+		for i, n := range names {
+			if n.Id == 0 {
+				id, ok := lookupName(m, n.Name)
+				if !ok {
+					return nil, fmt.Errorf("%s: unknown %s %q", d.Name, what, n.Name)
+				}
+				n.Id = id
+			}
+			resolved[i] = n
+		}
+		return resolved, nil
+	}
+	var err error
+	if d.Trackers, err = resolve(d.Trackers, trackers, "tracker"); err != nil {
+		return err
+	}
+	if d.Roles, err = resolve(d.Roles, roles, "role"); err != nil {
+		return err
+	}
+	d.TrackerIds = idSet(d.TrackerIds, d.Trackers)
+	d.RoleIds = idSet(d.RoleIds, d.Roles)
+	return nil
+}
+
+// PlanCustomFields returns the changes turning current into desired.
+// Definitions are matched by customized type and name, case-insensitively.
+func PlanCustomFields(current, desired []CustomFieldDefinition, prune bool) ([]CustomFieldChange, error) {
+	var changes []CustomFieldChange
+	matched := map[int]bool{}
+	for _, want := range desired {
+		have := LookupCustomField(current, want.CustomizedType, want.Name)
+		if have == nil {
+			// Redmine shows new custom fields unless told otherwise.
+			if !want.has("visible") {
+				want.Visible = true
+			}
+			changes = append(changes, CustomFieldChange{Action: "create", Definition: want})
+			continue
+		}
+		matched[have.Id] = true
+		fields := diffCustomField(&want, have)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "field_format" {
+			return nil, fmt.Errorf("%s: field format cannot be changed from %s to %s", want.Name, have.FieldFormat, want.FieldFormat)
+		}
+		changes = append(changes, CustomFieldChange{Action: "update", Definition: mergeCustomField(&want, have, fields), Fields: fields})
+	}
+	if prune {
+		for _, have := range current {
+			if !matched[have.Id] {
+				changes = append(changes, CustomFieldChange{Action: "delete", Definition: have})
+			}
+		}
+	}
+	return changes, nil
+}
+
+// ApplyCustomFields creates, updates and, with opts.Prune, deletes custom
+// fields so that the server matches desired. Trackers and roles of the
+// desired definitions may be given by id or by name. It returns the changes
+// made, or to be made with opts.DryRun, stopping at the first failure.
+// opts may be nil.
+func (c *Client) ApplyCustomFields(desired []CustomFieldDefinition, opts *ApplyOptions) ([]CustomFieldChange, error) {
+	if opts == nil {
+		opts = &ApplyOptions{}
+	}
+	current, err := c.CustomFields()
+	if err != nil {
+		return nil, err
+	}
+	trackers, err := c.Trackers()
+	if err != nil {
+		return nil, err
+	}
+	roles, err := c.Roles()
+	if err != nil {
+		return nil, err
+	}
+	trackerNames := map[int]string{}
+	for _, t := range trackers {
+		trackerNames[t.Id] = t.Name
+	}
+	roleNames := map[int]string{}
+	for _, r := range roles {
+		roleNames[r.Id] = r.Name
+	}
+
+	desired = append([]CustomFieldDefinition(nil), desired...)
+	for i := range desired {
+		if desired[i].Name == "" || desired[i].CustomizedType == "" {
+			return nil, errors.New("Custom field without name or customized type")
+		}
+		if err := desired[i].resolveIds(trackerNames, roleNames); err != nil {
+			return nil, err
+		}
+	}
+	changes, err := PlanCustomFields(current, desired, opts.Prune)
+	if err != nil || opts.DryRun {
+		return changes, err
+	}
+
+	for i, ch := range changes {
+		switch ch.Action {
+		case "create":
+			var created *CustomFieldDefinition
+			created, err = c.CreateCustomField(ch.Definition)
+			if err == nil {
+				changes[i].Definition.Id = created.Id
+			}
+		case "update":
+			err = c.UpdateCustomField(ch.Definition)
+		case "delete":
+			err = c.DeleteCustomField(ch.Definition.Id)
+		}
+		if err != nil {
+			return changes[:i], fmt.Errorf("%s: %w", ch, err)
+		}
+	}
+	return changes, nil
+}
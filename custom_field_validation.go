@@ -0,0 +1,205 @@
+package redmine
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CustomFieldError is a custom field value rejected by its definition.
+type CustomFieldError struct {
+	Id     int
+	Name   string
+	Value  string
+	Reason string
+}
+
+func (e CustomFieldError) Error() string {
+	name := e.Name
+	if name == "" {
+		name = "#" + strconv.Itoa(e.Id)
+	}
+	if e.Value == "" {
+		return name + ": " + e.Reason
+	}
+	return fmt.Sprintf("%s: %q %s", name, e.Value, e.Reason)
+}
+
+// CustomFieldErrors lists every invalid custom field value.
+type CustomFieldErrors []CustomFieldError
+
+func (e CustomFieldErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// CustomFieldValidator checks custom field values client-side against
+// the definitions returned by Client.CustomFields, the way Redmine does
+// when saving.
+type CustomFieldValidator struct {
+	definitions []CustomFieldDefinition
+}
+
+func NewCustomFieldValidator(definitions []CustomFieldDefinition) *CustomFieldValidator {
+	return &CustomFieldValidator{definitions: definitions}
+}
+
+// Definition returns the definition of the custom field of the given
+// customized type ("issue", "project", ...) with the given id, or with the
+// given name if id is 0.
+func (v *CustomFieldValidator) Definition(customizedType string, id int, name string) *CustomFieldDefinition {
+	if id == 0 {
+		return LookupCustomField(v.definitions, customizedType, name)
+	}
+	for i := range v.definitions {
+		d := &v.definitions[i]
+		if d.CustomizedType == customizedType && d.Id == id {
+			return d
+		}
+	}
+	return nil
+}
+
+// ValidateIssue validates the custom fields of the issue. For new issues
+// (Id 0), required fields of the issue's tracker have to be present.
+func (v *CustomFieldValidator) ValidateIssue(issue *Issue) error {
+	trackerId := issue.TrackerId
+	if trackerId == 0 && issue.Tracker != nil {
+		trackerId = issue.Tracker.Id
+	}
+	return v.validate("issue", issue.CustomFields, issue.Id == 0, func(d *CustomFieldDefinition) bool {
+		if len(d.Trackers) == 0 || trackerId == 0 {
+			return true
+		}
+		for _, t := range d.Trackers {
+			if t.Id == trackerId {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// ValidateProject validates the custom fields of the project.
+func (v *CustomFieldValidator) ValidateProject(project *Project) error {
+	return v.validate("project", project.CustomFields, project.Id == 0, nil)
+}
+
+// ValidateVersion validates the custom fields of the version.
+func (v *CustomFieldValidator) ValidateVersion(version *Version) error {
+	return v.validate("version", version.CustomFields, version.Id == 0, nil)
+}
+
+// ValidateTimeEntry validates the custom fields of the time entry.
+func (v *CustomFieldValidator) ValidateTimeEntry(timeEntry *TimeEntry) error {
+	return v.validate("time_entry", timeEntry.CustomFields, timeEntry.Id == 0, nil)
+}
+
+// ValidateUser validates the custom fields of the user.
+func (v *CustomFieldValidator) ValidateUser(user *User) error {
+	return v.validate("user", user.CustomFields, user.Id == 0, nil)
+}
+
+func (v *CustomFieldValidator) validate(customizedType string, fields []*CustomField, create bool, applies func(*CustomFieldDefinition) bool) error {
+	var errs CustomFieldErrors
+	present := map[int]bool{}
+	for _, cf := range fields {
+		d := v.Definition(customizedType, cf.Id, cf.Name)
+		if d == nil {
+			errs = append(errs, CustomFieldError{Id: cf.Id, Name: cf.Name, Reason: "is not a custom field of type " + customizedType})
+			continue
+		}
+		present[d.Id] = true
+		errs = append(errs, d.check(customFieldValues(cf.Value))...)
+	}
+	if create {
+		for i := range v.definitions {
+			d := &v.definitions[i]
+			if d.CustomizedType != customizedType || !d.IsRequired || present[d.Id] {
+				continue
+			}
+			if applies == nil || applies(d) {
+				errs = append(errs, CustomFieldError{Id: d.Id, Name: d.Name, Reason: "cannot be blank"})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// check returns the problems of values according to the definition.
+func (d *CustomFieldDefinition) check(values []string) []CustomFieldError {
+	var errs []CustomFieldError
+	fail := func(value, reason string) {
+		errs = append(errs, CustomFieldError{Id: d.Id, Name: d.Name, Value: value, Reason: reason})
+	}
+	if len(values) == 0 {
+		if d.IsRequired {
+			fail("", "cannot be blank")
+		}
+		return errs
+	}
+	if len(values) > 1 && !d.Multiple {
+		fail("", "does not accept multiple values")
+	}
+
+	var re *regexp.Regexp
+	if d.Regexp != "" {
+		var err error
+		if re, err = regexp.Compile(d.Regexp); err != nil {
+			// Ruby only syntax; leave it to the server.
+			re = nil
+		}
+	}
+	for _, value := range values {
+		length := utf8.RuneCountInString(value)
+		if d.MinLength != nil && *d.MinLength > 0 && length < *d.MinLength {
+			fail(value, fmt.Sprintf("is too short (minimum is %d characters)", *d.MinLength))
+		}
+		if d.MaxLength != nil && *d.MaxLength > 0 && length > *d.MaxLength {
+			fail(value, fmt.Sprintf("is too long (maximum is %d characters)", *d.MaxLength))
+		}
+		if re != nil && !re.MatchString(value) {
+			fail(value, "is invalid")
+		}
+		switch d.FieldFormat {
+		case "int":
+			if _, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err != nil {
+				fail(value, "is not a number")
+			}
+		case "float":
+			if _, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err != nil {
+				fail(value, "is invalid")
+			}
+		case "date":
+			if _, err := time.Parse("2006-01-02", value); err != nil {
+				fail(value, "is not a valid date")
+			}
+		case "bool":
+			if value != "0" && value != "1" {
+				fail(value, "is not included in the list")
+			}
+		case "list":
+			found := false
+			for _, pv := range d.PossibleValues {
+				found = found || pv.Value == value
+			}
+			if !found {
+				fail(value, "is not included in the list")
+			}
+		case "user", "version", "enumeration", "attachment":
+			if _, err := strconv.Atoi(value); err != nil {
+				fail(value, "is not a valid id")
+			}
+		}
+	}
+	return errs
+}
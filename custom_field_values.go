@@ -0,0 +1,223 @@
+package redmine
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CustomFieldList holds the custom field values of an issue, project,
+// version, user or time entry. Fields are looked up by name, compared
+// case-insensitively.
+type CustomFieldList []*CustomField
+
+// LookupCustomField returns the definition of the custom field of the given
+// customized type ("issue", "project", "version", "user", "time_entry")
+// with the given name, compared case-insensitively, or nil.
+func LookupCustomField(definitions []CustomFieldDefinition, customizedType string, name string) *CustomFieldDefinition {
+	for i := range definitions {
+		d := &definitions[i]
+		if d.CustomizedType == customizedType && strings.EqualFold(d.Name, name) {
+			return d
+		}
+	}
+	return nil
+}
+
+// Get returns the custom field with the given name, or nil.
+func (l CustomFieldList) Get(name string) *CustomField {
+	for _, cf := range l {
+		if strings.EqualFold(cf.Name, name) {
+			return cf
+		}
+	}
+	return nil
+}
+
+// Resolve fills in the names of the fields that only have an id, as built
+// by hand, and whether they are multiple, from the definitions.
+func (l CustomFieldList) Resolve(definitions []CustomFieldDefinition, customizedType string) {
+	for _, cf := range l {
+		for _, d := range definitions {
+			if d.CustomizedType == customizedType && d.Id == cf.Id {
+				if cf.Name == "" {
+					cf.Name = d.Name
+				}
+				cf.Multiple = d.Multiple
+			}
+		}
+	}
+}
+
+// Strings returns the values of the named field. It returns an error if the
+// field is not set.
+func (l CustomFieldList) Strings(name string) ([]string, error) {
+	cf := l.Get(name)
+	if cf == nil {
+		return nil, errors.New("Not Found")
+	}
+	return cf.Values(), nil
+}
+
+// StringValue returns the value of the named field, or "" if it is not set or
+// empty. Values of multiple fields are joined with ", ".
+func (l CustomFieldList) StringValue(name string) string {
+	values, _ := l.Strings(name)
+	return strings.Join(values, ", ")
+}
+
+// Int returns the value of the named field as an int, or 0 if it is empty.
+func (l CustomFieldList) Int(name string) (int, error) {
+	values, err := l.Ints(name)
+	if err != nil || len(values) == 0 {
+		return 0, err
+	}
+	return values[0], nil
+}
+
+// Ints returns the values of the named field as ints.
+func (l CustomFieldList) Ints(name string) ([]int, error) {
+	values, err := l.Strings(name)
+	if err != nil {
+		return nil, err
+	}
+	ints := make([]int, len(values))
+	for i, v := range values {
+		if ints[i], err = strconv.Atoi(strings.TrimSpace(v)); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return ints, nil
+}
+
+// Float returns the value of the named field as a float, or 0 if it is
+// empty.
+func (l CustomFieldList) Float(name string) (float64, error) {
+	values, err := l.Strings(name)
+	if err != nil || len(values) == 0 {
+		return 0, err
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(values[0]), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return f, nil
+}
+
+// Date returns the value of the named date field, or the zero time if it
+// is empty.
+func (l CustomFieldList) Date(name string) (time.Time, error) {
+	values, err := l.Strings(name)
+	if err != nil || len(values) == 0 {
+		return time.Time{}, err
+	}
+	t, err := time.Parse("2006-01-02", values[0])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return t, nil
+}
+
+// Bool returns the value of the named boolean field, false if it is empty.
+func (l CustomFieldList) Bool(name string) (bool, error) {
+	values, err := l.Strings(name)
+	if err != nil || len(values) == 0 {
+		return false, err
+	}
+	switch values[0] {
+	case "1", "true":
+		return true, nil
+	case "0", "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("%s: invalid boolean %q", name, values[0])
+}
+
+// customFieldString formats a single value the way Redmine expects it.
+func customFieldString(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case time.Time:
+		return value.Format("2006-01-02")
+	case bool:
+		if value {
+			return "1"
+		}
+		return "0"
+	case float32:
+		return strconv.FormatFloat(float64(value), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return fmt.Sprint(v)
+}
+
+// Set sets the value of the field defined by definition, adding it if
+// missing. value may be nil, a string, an int, a float, a bool, a time.Time
+// for dates, or a slice of those for multiple fields.
+func (l *CustomFieldList) Set(definition *CustomFieldDefinition, value interface{}) error {
+	var values []string
+	switch v := value.(type) {
+	case nil:
+	case []string:
+		values = v
+	case []int:
+		for _, e := range v {
+			values = append(values, strconv.Itoa(e))
+		}
+	case []interface{}:
+		for _, e := range v {
+			values = append(values, customFieldString(e))
+		}
+	default:
+		values = []string{customFieldString(v)}
+	}
+
+	var fieldValue interface{}
+	switch {
+	case definition.Multiple:
+		if values == nil {
+			values = []string{}
+		}
+		fieldValue = values
+	case len(values) > 1:
+		return errors.New(definition.Name + ": does not accept multiple values")
+	case len(values) == 1:
+		fieldValue = values[0]
+	default:
+		fieldValue = ""
+	}
+
+	for _, cf := range *l {
+		if cf.Id == definition.Id {
+			cf.Value = fieldValue
+			cf.Multiple = definition.Multiple
+			return nil
+		}
+	}
+	*l = append(*l, &CustomField{
+		Id:       definition.Id,
+		Name:     definition.Name,
+		Multiple: definition.Multiple,
+		Value:    fieldValue,
+	})
+	return nil
+}
+
+// SetByName sets the value of the named field of the given customized type,
+// see Set.
+func (l *CustomFieldList) SetByName(definitions []CustomFieldDefinition, customizedType string, name string, value interface{}) error {
+	d := LookupCustomField(definitions, customizedType, name)
+	if d == nil {
+		return errors.New("Unknown custom field " + name)
+	}
+	return l.Set(d, value)
+}
+
+// Values returns the values of the field, empty values left out.
+func (cf *CustomField) Values() []string {
+	return customFieldValues(cf.Value)
+}
@@ -0,0 +1,134 @@
+package redmine
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testCustomFields decodes the custom fields as returned by Redmine.
+func testCustomFields(t *testing.T) CustomFieldList {
+	t.Helper()
+	var l CustomFieldList
+	err := json.Unmarshal([]byte(`[
+		{"id": 1, "name": "Count", "value": "42"},
+		{"id": 2, "name": "Counts", "multiple": true, "value": ["3", "5"]},
+		{"id": 3, "name": "Ratio", "value": "0.25"},
+		{"id": 4, "name": "Due", "value": "2024-02-01"},
+		{"id": 5, "name": "Flag", "value": "1"},
+		{"id": 6, "name": "Flags", "multiple": true, "value": ["0", "1"]},
+		{"id": 7, "name": "Empty", "value": ""},
+		{"id": 8, "name": "Bad", "value": "x"}
+	]`), &l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestCustomFieldListAccessors(t *testing.T) {
+	due, _ := time.Parse("2006-01-02", "2024-02-01")
+	tests := []struct {
+		name string
+		get  func(l CustomFieldList, name string) (interface{}, error)
+		want map[string]interface{} // by field, nil for an error
+	}{
+		{
+			name: "Int",
+			get:  func(l CustomFieldList, name string) (interface{}, error) { return l.Int(name) },
+			want: map[string]interface{}{"count": 42, "Counts": 3, "Empty": 0, "Bad": nil, "Missing": nil},
+		},
+		{
+			name: "Ints",
+			get:  func(l CustomFieldList, name string) (interface{}, error) { return l.Ints(name) },
+			want: map[string]interface{}{"Count": []int{42}, "Counts": []int{3, 5}, "Empty": []int{}, "Bad": nil},
+		},
+		{
+			name: "Float",
+			get:  func(l CustomFieldList, name string) (interface{}, error) { return l.Float(name) },
+			want: map[string]interface{}{"Ratio": 0.25, "Counts": 3.0, "Empty": 0.0, "Bad": nil},
+		},
+		{
+			name: "Date",
+			get:  func(l CustomFieldList, name string) (interface{}, error) { return l.Date(name) },
+			want: map[string]interface{}{"Due": due, "Empty": time.Time{}, "Bad": nil},
+		},
+		{
+			name: "Bool",
+			get:  func(l CustomFieldList, name string) (interface{}, error) { return l.Bool(name) },
+			want: map[string]interface{}{"Flag": true, "Flags": false, "Empty": false, "Bad": nil},
+		},
+		{
+			name: "StringValue",
+			get:  func(l CustomFieldList, name string) (interface{}, error) { return l.StringValue(name), nil },
+			want: map[string]interface{}{"Count": "42", "Counts": "3, 5", "Empty": "", "Missing": ""},
+		},
+	}
+	l := testCustomFields(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for field, want := range tt.want {
+				got, err := tt.get(l, field)
+				if want == nil {
+					if err == nil {
+						t.Errorf("%s(%q) = %v, want an error", tt.name, field, got)
+					}
+					continue
+				}
+				if err != nil || !reflect.DeepEqual(got, want) {
+					t.Errorf("%s(%q) = %v, %v, want %v", tt.name, field, got, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCustomFieldListSet(t *testing.T) {
+	due, _ := time.Parse("2006-01-02", "2024-02-01")
+	single := &CustomFieldDefinition{Id: 1, Name: "Single"}
+	multiple := &CustomFieldDefinition{Id: 2, Name: "Multiple", Multiple: true}
+	tests := []struct {
+		name  string
+		def   *CustomFieldDefinition
+		value interface{}
+		want  interface{}
+		err   bool
+	}{
+		{"string", single, "a", "a", false},
+		{"int", single, 7, "7", false},
+		{"float", single, 1.5, "1.5", false},
+		{"bool", single, true, "1", false},
+		{"date", single, due, "2024-02-01", false},
+		{"nil", single, nil, "", false},
+		{"single slice", single, []string{"a"}, "a", false},
+		{"too many values", single, []string{"a", "b"}, nil, true},
+		{"strings", multiple, []string{"a", "b"}, []string{"a", "b"}, false},
+		{"ints", multiple, []int{1, 2}, []string{"1", "2"}, false},
+		{"mixed", multiple, []interface{}{1, false}, []string{"1", "0"}, false},
+		{"single value", multiple, "a", []string{"a"}, false},
+		{"cleared", multiple, nil, []string{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := CustomFieldList{{Id: 1, Name: "Single", Value: "old"}}
+			err := l.Set(tt.def, tt.value)
+			if tt.err {
+				if err == nil {
+					t.Errorf("Set(%v) succeeded, want an error", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			cf := l.Get(tt.def.Name)
+			if cf == nil || cf.Id != tt.def.Id || cf.Multiple != tt.def.Multiple || !reflect.DeepEqual(cf.Value, tt.want) {
+				t.Errorf("Set(%v) = %+v, want value %#v", tt.value, cf, tt.want)
+			}
+			if len(l) != int(tt.def.Id) {
+				t.Errorf("Set(%v) left %d fields", tt.value, len(l))
+			}
+		})
+	}
+}
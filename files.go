@@ -0,0 +1,94 @@
+package redmine
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+type projectFilesResult struct {
+	Files []ProjectFile `json:"files"`
+}
+
+type projectFileRequest struct {
+	File projectFileUpload `json:"file"`
+}
+
+type projectFileUpload struct {
+	Token       string `json:"token"`
+	VersionId   int    `json:"version_id,omitempty"`
+	Filename    string `json:"filename,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ProjectFile is a file published in the Files module of a project.
+type ProjectFile struct {
+	Attachment
+	Version   *IdName `json:"version,omitempty"`
+	Downloads int     `json:"downloads"`
+}
+
+// ProjectFiles fetches the files published in the given project.
+func (c *Client) ProjectFiles(projectId int) ([]ProjectFile, error) {
+	req, err := c.NewRequest("GET", "/projects/"+strconv.Itoa(projectId)+"/files.json", nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	decoder := json.NewDecoder(res.Body)
+	var r projectFilesResult
+	if res.StatusCode == 404 {
+		return nil, errors.New("Not Found")
+	}
+	if res.StatusCode != 200 {
+		var er errorsResult
+		err = decoder.Decode(&er)
+		if err == nil {
+			err = errors.New(strings.Join(er.Errors, "\n"))
+		}
+	} else {
+		err = decoder.Decode(&r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.Files, nil
+}
+
+// AddProjectFile publishes an uploaded file in the given project.
+// versionId may be 0 to publish the file without a version.
+func (c *Client) AddProjectFile(projectId int, upload *Upload, versionId int, description string) error {
+	var fr projectFileRequest
+	fr.File.Token = upload.Token
+	fr.File.VersionId = versionId
+	fr.File.Filename = upload.Filename
+	fr.File.Description = description
+	s, err := json.Marshal(fr)
+	if err != nil {
+		return err
+	}
+	req, err := c.NewRequest("POST", "/projects/"+strconv.Itoa(projectId)+"/files.json", strings.NewReader(string(s)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return errors.New("Not Found")
+	}
+	if res.StatusCode/100 != 2 {
+		err = errorFromResp(json.NewDecoder(res.Body), res.StatusCode)
+	}
+	return err
+}
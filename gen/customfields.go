@@ -0,0 +1,305 @@
+// Package gen generates Go code from the configuration of a Redmine
+// instance.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"bsky.watch/redmine"
+)
+
+// goName turns s into an exported Go identifier: "due date" and "due_date"
+// become "DueDate".
+func goName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	name := b.String()
+	if name == "" || !unicode.IsLetter([]rune(name)[0]) {
+		name = "X" + name
+	}
+	return name
+}
+
+// namer hands out unique identifiers.
+type namer map[string]bool
+
+func (n namer) name(s string) string {
+	name := s
+	for i := 2; n[name]; i++ {
+		name = s + strconv.Itoa(i)
+	}
+	n[name] = true
+	return name
+}
+
+// field is a custom field as generated.
+type field struct {
+	def      *redmine.CustomFieldDefinition
+	name     string // struct field name
+	idConst  string
+	enumType string // for list fields with possible values
+	enums    []enumValue
+}
+
+type enumValue struct {
+	name  string
+	value string
+}
+
+// elemType returns the Go type of a single value of the field.
+func (f *field) elemType() string {
+	if f.enumType != "" {
+		return f.enumType
+	}
+	switch f.def.FieldFormat {
+	case "int", "user", "version", "enumeration", "attachment":
+		return "int"
+	case "float":
+		return "float64"
+	case "bool":
+		return "bool"
+	case "date":
+		return "time.Time"
+	}
+	return "string"
+}
+
+// goType returns the type of the struct field. Optional numbers and
+// booleans are pointers so that unset values are not sent as zero.
+func (f *field) goType() string {
+	t := f.elemType()
+	if f.def.Multiple {
+		return "[]" + t
+	}
+	switch t {
+	case "int", "float64", "bool":
+		return "*" + t
+	}
+	return t
+}
+
+type generator struct {
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// CustomFields writes the Go source of package pkg declaring, for each
+// customized type found in definitions:
+//
+//   - a constant with the id of each custom field, such as IssueSeverityId,
+//   - a string type with constants for the possible values of list fields,
+//   - a struct type such as IssueCustomFields with a typed field per custom
+//     field, and its UnmarshalCustomFields and CustomFields methods
+//     converting from and to redmine.CustomFieldList.
+func CustomFields(w io.Writer, pkg string, definitions []redmine.CustomFieldDefinition) error {
+	byType := map[string][]*redmine.CustomFieldDefinition{}
+	for i := range definitions {
+		d := &definitions[i]
+		byType[d.CustomizedType] = append(byType[d.CustomizedType], d)
+	}
+	types := make([]string, 0, len(byType))
+	for t := range byType {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	g := &generator{imports: map[string]bool{}}
+	global := namer{}
+	for _, t := range types {
+		defs := byType[t]
+		sort.Slice(defs, func(i, j int) bool { return defs[i].Id < defs[j].Id })
+		prefix := goName(t)
+		// Fields can't have the names of the methods of the struct.
+		fieldNames := namer{"CustomFields": true, "UnmarshalCustomFields": true}
+		fields := make([]*field, len(defs))
+		for i, d := range defs {
+			f := &field{def: d, name: fieldNames.name(goName(d.Name))}
+			f.idConst = global.name(prefix + f.name + "Id")
+			if d.FieldFormat == "list" && len(d.PossibleValues) > 0 {
+				f.enumType = global.name(prefix + f.name)
+				for _, pv := range d.PossibleValues {
+					f.enums = append(f.enums, enumValue{global.name(f.enumType + goName(pv.Value)), pv.Value})
+				}
+			}
+			fields[i] = f
+		}
+		g.customizedType(t, prefix, global.name(prefix+"CustomFields"), fields)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by godmine gen customfields; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n", pkg)
+	// Without definitions there is nothing to import.
+	if len(types) > 0 {
+		out.WriteString("\nimport (\n")
+		imports := make([]string, 0, len(g.imports))
+		for imp := range g.imports {
+			imports = append(imports, imp)
+		}
+		sort.Strings(imports)
+		for _, imp := range imports {
+			fmt.Fprintf(&out, "\t%q\n", imp)
+		}
+		out.WriteString("\n\t\"bsky.watch/redmine\"\n)\n")
+	}
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+func (g *generator) customizedType(customizedType, prefix, structName string, fields []*field) {
+	g.printf("\n// Ids of the %s custom fields.\nconst (\n", customizedType)
+	for _, f := range fields {
+		g.printf("\t%s = %d // %s\n", f.idConst, f.def.Id, f.def.Name)
+	}
+	g.printf(")\n")
+
+	for _, f := range fields {
+		if f.enumType == "" {
+			continue
+		}
+		g.printf("\n// %s is a value of the %q %s custom field.\n", f.enumType, f.def.Name, customizedType)
+		g.printf("type %s string\n\nconst (\n", f.enumType)
+		for _, e := range f.enums {
+			g.printf("\t%s %s = %q\n", e.name, f.enumType, e.value)
+		}
+		g.printf(")\n")
+	}
+
+	g.printf("\n// %s holds the %s custom fields.\ntype %s struct {\n", structName, customizedType, structName)
+	for _, f := range fields {
+		g.printf("\t%s %s // %s\n", f.name, f.goType(), f.def.Name)
+		if f.elemType() == "time.Time" {
+			g.imports["time"] = true
+		}
+	}
+	g.printf("}\n")
+
+	g.printf("\n// UnmarshalCustomFields sets the fields from the values of l.\n")
+	g.printf("func (f *%s) UnmarshalCustomFields(l redmine.CustomFieldList) error {\n", structName)
+	g.printf("\tfor _, cf := range l {\n\t\tvalues := cf.Values()\n\t\tswitch cf.Id {\n")
+	for _, f := range fields {
+		g.printf("\t\tcase %s:\n", f.idConst)
+		g.unmarshal(f)
+	}
+	g.printf("\t\t}\n\t}\n\treturn nil\n}\n")
+
+	g.printf("\n// CustomFields returns the fields as custom field values. Unset fields are\n// empty, which clears them when updating.\n")
+	g.printf("func (f *%s) CustomFields() redmine.CustomFieldList {\n", structName)
+	g.printf("\tvar l redmine.CustomFieldList\n")
+	for _, f := range fields {
+		g.marshal(f)
+	}
+	g.printf("\treturn l\n}\n")
+}
+
+// parse returns the statements parsing the string expression src into a
+// new variable v of the field's element type.
+func (g *generator) parse(f *field, src string) string {
+	switch f.elemType() {
+	case "int":
+		g.imports["strconv"] = true
+		return fmt.Sprintf("v, err := strconv.Atoi(%s)\nif err != nil {\nreturn err\n}\n", src)
+	case "float64":
+		g.imports["strconv"] = true
+		return fmt.Sprintf("v, err := strconv.ParseFloat(%s, 64)\nif err != nil {\nreturn err\n}\n", src)
+	case "bool":
+		return fmt.Sprintf("v := %s == \"1\" || %s == \"true\"\n", src, src)
+	case "time.Time":
+		return fmt.Sprintf("v, err := time.Parse(\"2006-01-02\", %s)\nif err != nil {\nreturn err\n}\n", src)
+	case "string":
+		return fmt.Sprintf("v := %s\n", src)
+	}
+	return fmt.Sprintf("v := %s(%s)\n", f.elemType(), src)
+}
+
+// format returns an expression formatting the value expression src of the
+// field's element type as a string.
+func (g *generator) format(f *field, src string) string {
+	switch f.elemType() {
+	case "int":
+		g.imports["strconv"] = true
+		return fmt.Sprintf("strconv.Itoa(%s)", src)
+	case "float64":
+		g.imports["strconv"] = true
+		return fmt.Sprintf("strconv.FormatFloat(%s, 'f', -1, 64)", src)
+	case "bool":
+		return fmt.Sprintf("map[bool]string{false: \"0\", true: \"1\"}[%s]", src)
+	case "time.Time":
+		return fmt.Sprintf("%s.Format(\"2006-01-02\")", src)
+	case "string":
+		return src
+	}
+	return fmt.Sprintf("string(%s)", src)
+}
+
+func (g *generator) unmarshal(f *field) {
+	if f.def.Multiple {
+		g.printf("\t\t\tf.%s = nil\n\t\t\tfor _, s := range values {\n", f.name)
+		g.printf("%s", g.parse(f, "s"))
+		g.printf("\t\t\t\tf.%s = append(f.%s, v)\n\t\t\t}\n", f.name, f.name)
+		return
+	}
+	value := "v"
+	switch {
+	case strings.HasPrefix(f.goType(), "*"):
+		g.printf("\t\t\tf.%s = nil\n", f.name)
+		value = "&v"
+	case f.goType() == "time.Time":
+		g.printf("\t\t\tf.%s = time.Time{}\n", f.name)
+	default:
+		g.printf("\t\t\tf.%s = \"\"\n", f.name)
+	}
+	g.printf("\t\t\tif len(values) > 0 {\n")
+	g.printf("%s", g.parse(f, "values[0]"))
+	g.printf("\t\t\t\tf.%s = %s\n\t\t\t}\n", f.name, value)
+}
+
+func (g *generator) marshal(f *field) {
+	id := f.idConst
+	if f.def.Multiple {
+		g.printf("\t{\n\t\tvalues := []string{}\n\t\tfor _, e := range f.%s {\n", f.name)
+		g.printf("\t\t\tvalues = append(values, %s)\n\t\t}\n", g.format(f, "e"))
+		g.printf("\t\tl = append(l, &redmine.CustomField{Id: %s, Name: %q, Multiple: true, Value: values})\n\t}\n", id, f.def.Name)
+		return
+	}
+	switch {
+	case strings.HasPrefix(f.goType(), "*"):
+		g.printf("\tif f.%s != nil {\n", f.name)
+		g.printf("\t\tl = append(l, &redmine.CustomField{Id: %s, Name: %q, Value: %s})\n", id, f.def.Name, g.format(f, "*f."+f.name))
+		g.printf("\t} else {\n\t\tl = append(l, &redmine.CustomField{Id: %s, Name: %q, Value: \"\"})\n\t}\n", id, f.def.Name)
+	case f.goType() == "time.Time":
+		g.printf("\tif !f.%s.IsZero() {\n", f.name)
+		g.printf("\t\tl = append(l, &redmine.CustomField{Id: %s, Name: %q, Value: %s})\n", id, f.def.Name, g.format(f, "f."+f.name))
+		g.printf("\t} else {\n\t\tl = append(l, &redmine.CustomField{Id: %s, Name: %q, Value: \"\"})\n\t}\n", id, f.def.Name)
+	default:
+		g.printf("\tl = append(l, &redmine.CustomField{Id: %s, Name: %q, Value: %s})\n", id, f.def.Name, g.format(f, "f."+f.name))
+	}
+}
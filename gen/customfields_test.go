@@ -0,0 +1,145 @@
+package gen
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"bsky.watch/redmine"
+)
+
+func TestGoName(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"due date", "DueDate"},
+		{"due_date", "DueDate"},
+		{"Severity", "Severity"},
+		{"2nd reviewer", "X2ndReviewer"},
+		{"!!", "X"},
+	}
+	for _, tt := range tests {
+		if got := goName(tt.in); got != tt.want {
+			t.Errorf("goName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+var testDefinitions = []redmine.CustomFieldDefinition{
+	{Id: 1, Name: "Severity", CustomizedType: "issue", FieldFormat: "list",
+		PossibleValues: []redmine.CustomFieldPossibleValue{{Value: "Low"}, {Value: "High"}}},
+	{Id: 2, Name: "Platforms", CustomizedType: "issue", FieldFormat: "list", Multiple: true,
+		PossibleValues: []redmine.CustomFieldPossibleValue{{Value: "Linux"}, {Value: "Mac OS"}}},
+	{Id: 3, Name: "Estimate", CustomizedType: "issue", FieldFormat: "float"},
+	{Id: 4, Name: "Review date", CustomizedType: "issue", FieldFormat: "date"},
+	{Id: 5, Name: "Reviewer", CustomizedType: "issue", FieldFormat: "user"},
+	{Id: 6, Name: "Blocker", CustomizedType: "issue", FieldFormat: "bool"},
+	{Id: 7, Name: "Custom fields", CustomizedType: "issue", FieldFormat: "string"},
+	{Id: 8, Name: "Unmarshal custom fields", CustomizedType: "user", FieldFormat: "text"},
+}
+
+// roundTripTest is compiled with the generated code of testDefinitions.
+const roundTripTest = `package cftest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	estimate, reviewer, blocker := 1.5, 7, true
+	want := IssueCustomFields{
+		Severity:      IssueSeverityHigh,
+		Platforms:     []IssuePlatforms{IssuePlatformsLinux, IssuePlatformsMacOS},
+		Estimate:      &estimate,
+		ReviewDate:    time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		Reviewer:      &reviewer,
+		Blocker:       &blocker,
+		CustomFields2: "text",
+	}
+	var got IssueCustomFields
+	if err := got.UnmarshalCustomFields(want.CustomFields()); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	var unset IssueCustomFields
+	if err := unset.UnmarshalCustomFields(unset.CustomFields()); err != nil || !reflect.DeepEqual(unset, IssueCustomFields{}) {
+		t.Errorf("unset fields came back as %+v, %v", unset, err)
+	}
+	user := UserCustomFields{UnmarshalCustomFields2: "x"}
+	var userGot UserCustomFields
+	if err := userGot.UnmarshalCustomFields(user.CustomFields()); err != nil || userGot != user {
+		t.Errorf("user fields = %+v, %v", userGot, err)
+	}
+}
+`
+
+func generate(t *testing.T, pkg string, definitions []redmine.CustomFieldDefinition) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := CustomFields(&buf, pkg, definitions); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestCustomFieldsNames(t *testing.T) {
+	src := generate(t, "cftest", testDefinitions)
+	// Ignores the alignment of gofmt.
+	words := strings.Join(strings.Fields(src), " ")
+	for _, s := range []string{
+		"IssueSeverityId = 1",
+		"IssueSeverityHigh IssueSeverity = \"High\"",
+		"IssuePlatformsMacOS IssuePlatforms = \"Mac OS\"",
+		"CustomFields2 string",
+		"UnmarshalCustomFields2 string",
+		"Estimate *float64",
+		"ReviewDate time.Time",
+	} {
+		if !strings.Contains(words, s) {
+			t.Errorf("generated code lacks %q:\n%s", s, src)
+		}
+	}
+}
+
+// TestCustomFieldsCompile builds and tests the generated code with the go
+// command, in a directory of this module so that it can import redmine.
+func TestCustomFieldsCompile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	gocmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	dir, err := ioutil.TempDir(".", "cftest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"cftest/customfields.go":      generate(t, "cftest", testDefinitions),
+		"cftest/customfields_test.go": roundTripTest,
+		"empty/customfields.go":       generate(t, "empty", nil),
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(gocmd, "test", "./"+filepath.Base(dir)+"/...")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("go test: %v\n%s", err, out)
+	}
+}
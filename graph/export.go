@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func (g *Graph) label(id int) string {
+	if issue, ok := g.Issues[id]; ok {
+		return fmt.Sprintf("#%d %s", id, issue.Subject)
+	}
+	return fmt.Sprintf("#%d", id)
+}
+
+func edgeLabel(e Edge) string {
+	if e.Delay != 0 && e.Type == "precedes" {
+		return fmt.Sprintf("%s (%dd)", e.Type, e.Delay)
+	}
+	return e.Type
+}
+
+// WriteDOT writes the graph in Graphviz DOT format.
+// Non-dependency relations are drawn dashed.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph issues {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, id := range g.ids() {
+		fmt.Fprintf(&b, "\t%d [label=%q];\n", id, g.label(id))
+	}
+	for _, e := range g.Edges {
+		style := ""
+		if !e.Dependency {
+			style = ", style=dashed, dir=none"
+		}
+		fmt.Fprintf(&b, "\t%d -> %d [label=%q%s];\n", e.From, e.To, edgeLabel(e), style)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart.
+// Non-dependency relations are drawn dotted.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for _, id := range g.ids() {
+		label := strings.Replace(g.label(id), `"`, "#quot;", -1)
+		fmt.Fprintf(&b, "    i%d[\"%s\"]\n", id, label)
+	}
+	for _, e := range g.Edges {
+		arrow := "-->"
+		if !e.Dependency {
+			arrow = "-.-"
+		}
+		fmt.Fprintf(&b, "    i%d %s|%s| i%d\n", e.From, arrow, edgeLabel(e), e.To)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
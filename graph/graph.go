@@ -0,0 +1,341 @@
+// Package graph builds dependency graphs from Redmine issue relations.
+//
+// Only "blocks" and "precedes" relations (and their reverse forms
+// "blocked" and "follows") are dependencies: an edge from A to B means that
+// A has to be finished before B can start. Other relations such as
+// "relates" or "duplicates" are kept for export but ignored by the analysis.
+package graph
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"bsky.watch/redmine"
+)
+
+// HoursPerDay converts estimated hours into days for issues without
+// start and due dates.
+var HoursPerDay float64 = 8
+
+// ErrCycle is returned when an operation requires an acyclic graph.
+var ErrCycle = errors.New("dependency cycle detected")
+
+// Edge is a relation between two issues, oriented so that From has to be
+// finished before To for dependency relations.
+type Edge struct {
+	From       int
+	To         int
+	Type       string
+	Delay      int // in days, only meaningful for "precedes"
+	Dependency bool
+}
+
+type Graph struct {
+	Issues map[int]*redmine.Issue
+	Edges  []Edge
+
+	seen map[int]bool // relation ids already added
+}
+
+func New() *Graph {
+	return &Graph{
+		Issues: map[int]*redmine.Issue{},
+		seen:   map[int]bool{},
+	}
+}
+
+// AddIssue adds issue as a node of the graph.
+func (g *Graph) AddIssue(issue *redmine.Issue) {
+	g.Issues[issue.Id] = issue
+}
+
+// AddRelation adds relation as an edge of the graph.
+// Relations already added (by Id) are ignored.
+func (g *Graph) AddRelation(relation redmine.IssueRelation) {
+	if relation.Id != 0 {
+		if g.seen[relation.Id] {
+			return
+		}
+		g.seen[relation.Id] = true
+	}
+	delay, _ := strconv.Atoi(relation.Delay)
+	e := Edge{From: relation.IssueId, To: relation.IssueToId, Type: relation.RelationType, Delay: delay}
+	switch relation.RelationType {
+	case "blocks", "precedes":
+		e.Dependency = true
+	case "blocked":
+		e.From, e.To, e.Type, e.Dependency = e.To, e.From, "blocks", true
+	case "follows":
+		e.From, e.To, e.Type, e.Dependency = e.To, e.From, "precedes", true
+	}
+	g.Edges = append(g.Edges, e)
+}
+
+// Crawl builds the graph of the given issues and every issue reachable from
+// them through relations.
+func Crawl(c *redmine.Client, issueIds ...int) (*Graph, error) {
+	g := New()
+	queue := append([]int{}, issueIds...)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if _, ok := g.Issues[id]; ok {
+			continue
+		}
+		issue, err := c.Issue(id)
+		if err != nil {
+			return nil, err
+		}
+		g.AddIssue(issue)
+		relations, err := c.IssueRelations(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range relations {
+			g.AddRelation(r)
+			queue = append(queue, r.IssueId, r.IssueToId)
+		}
+	}
+	return g, nil
+}
+
+// CrawlProject builds the graph of the open issues of the given project.
+// Issues of other projects related to them are added as nodes, but their
+// own relations are not followed.
+func CrawlProject(c *redmine.Client, projectId int) (*Graph, error) {
+	issues, err := c.IssuesOf(projectId)
+	if err != nil {
+		return nil, err
+	}
+	g := New()
+	for i := range issues {
+		g.AddIssue(&issues[i])
+	}
+	for i := range issues {
+		relations, err := c.IssueRelations(issues[i].Id)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range relations {
+			g.AddRelation(r)
+		}
+	}
+	for _, e := range g.Edges {
+		for _, id := range []int{e.From, e.To} {
+			if _, ok := g.Issues[id]; ok {
+				continue
+			}
+			issue, err := c.Issue(id)
+			if err != nil {
+				return nil, err
+			}
+			g.AddIssue(issue)
+		}
+	}
+	return g, nil
+}
+
+// ids returns the ids of all nodes in ascending order.
+func (g *Graph) ids() []int {
+	ids := make([]int, 0, len(g.Issues))
+	for id := range g.Issues {
+		ids = append(ids, id)
+	}
+	for _, e := range g.Edges {
+		if _, ok := g.Issues[e.From]; !ok {
+			ids = append(ids, e.From)
+		}
+		if _, ok := g.Issues[e.To]; !ok {
+			ids = append(ids, e.To)
+		}
+	}
+	sort.Ints(ids)
+	j := 0
+	for i, id := range ids {
+		if i == 0 || id != ids[j-1] {
+			ids[j] = id
+			j++
+		}
+	}
+	return ids[:j]
+}
+
+// successors returns the dependency edges grouped by their origin.
+func (g *Graph) successors() map[int][]Edge {
+	succ := map[int][]Edge{}
+	for _, e := range g.Edges {
+		if e.Dependency {
+			succ[e.From] = append(succ[e.From], e)
+		}
+	}
+	return succ
+}
+
+// Cycles returns the dependency cycles of the graph, each one as the list of
+// issue ids taking part in it, in ascending order.
+func (g *Graph) Cycles() [][]int {
+	succ := g.successors()
+	index := map[int]int{}
+	low := map[int]int{}
+	onStack := map[int]bool{}
+	var stack []int
+	var cycles [][]int
+	next := 0
+
+	var visit func(v int)
+	visit = func(v int) {
+		index[v] = next
+		low[v] = next
+		next++
+		stack = append(stack, v)
+		onStack[v] = true
+		selfLoop := false
+		for _, e := range succ[v] {
+			if e.To == v {
+				selfLoop = true
+			}
+			if _, ok := index[e.To]; !ok {
+				visit(e.To)
+				if low[e.To] < low[v] {
+					low[v] = low[e.To]
+				}
+			} else if onStack[e.To] && index[e.To] < low[v] {
+				low[v] = index[e.To]
+			}
+		}
+		if low[v] != index[v] {
+			return
+		}
+		var scc []int
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			scc = append(scc, w)
+			if w == v {
+				break
+			}
+		}
+		if len(scc) > 1 || selfLoop {
+			sort.Ints(scc)
+			cycles = append(cycles, scc)
+		}
+	}
+	for _, id := range g.ids() {
+		if _, ok := index[id]; !ok {
+			visit(id)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// TopologicalOrder returns the issue ids so that every issue comes after the
+// issues it depends on. Ties are broken by ascending id.
+// It returns ErrCycle if the dependencies are cyclic.
+func (g *Graph) TopologicalOrder() ([]int, error) {
+	succ := g.successors()
+	ids := g.ids()
+	indegree := map[int]int{}
+	for _, e := range g.Edges {
+		if e.Dependency {
+			indegree[e.To]++
+		}
+	}
+	var ready []int
+	for _, id := range ids {
+		if indegree[id] == 0 {
+			ready = append(ready, id)
+		}
+	}
+	order := make([]int, 0, len(ids))
+	for len(ready) > 0 {
+		sort.Ints(ready)
+		id := ready[0]
+		ready = ready[1:]
+		order = append(order, id)
+		for _, e := range succ[id] {
+			indegree[e.To]--
+			if indegree[e.To] == 0 {
+				ready = append(ready, e.To)
+			}
+		}
+	}
+	if len(order) != len(ids) {
+		return nil, ErrCycle
+	}
+	return order, nil
+}
+
+// Duration returns the expected duration of the issue in days.
+// It is the span between start and due dates when both are set, and the
+// estimated hours divided by HoursPerDay otherwise.
+// Issues unknown to the graph have no duration.
+func (g *Graph) Duration(id int) float64 {
+	issue, ok := g.Issues[id]
+	if !ok {
+		return 0
+	}
+	start, err1 := time.Parse("2006-01-02", issue.StartDate)
+	due, err2 := time.Parse("2006-01-02", issue.DueDate)
+	if err1 == nil && err2 == nil && !due.Before(start) {
+		return due.Sub(start).Hours()/24 + 1
+	}
+	if HoursPerDay > 0 {
+		return float64(issue.EstimatedHours) / HoursPerDay
+	}
+	return 0
+}
+
+// CriticalPath is the longest chain of dependent issues.
+type CriticalPath struct {
+	Issues []int
+	Days   float64 // durations plus "precedes" delays
+}
+
+// CriticalPath computes the longest chain of dependencies, weighting each
+// issue by its Duration and each "precedes" edge by its delay.
+// It returns ErrCycle if the dependencies are cyclic.
+func (g *Graph) CriticalPath() (*CriticalPath, error) {
+	order, err := g.TopologicalOrder()
+	if err != nil {
+		return nil, err
+	}
+	succ := g.successors()
+	start := map[int]float64{}
+	finish := map[int]float64{}
+	prev := map[int]int{}
+	// The topological order guarantees that the start of an issue is final
+	// once all of its predecessors have been visited.
+	for _, id := range order {
+		finish[id] = start[id] + g.Duration(id)
+		for _, e := range succ[id] {
+			delay := 0.0
+			if e.Type == "precedes" {
+				delay = float64(e.Delay)
+			}
+			s := finish[id] + delay
+			if _, ok := prev[e.To]; !ok || s > start[e.To] {
+				start[e.To] = s
+				prev[e.To] = id
+			}
+		}
+	}
+	cp := &CriticalPath{}
+	if len(order) == 0 {
+		return cp, nil
+	}
+	last := order[0]
+	for _, id := range order {
+		if finish[id] > finish[last] {
+			last = id
+		}
+	}
+	cp.Days = finish[last]
+	for id, ok := last, true; ok; id, ok = prev[id] {
+		cp.Issues = append([]int{id}, cp.Issues...)
+	}
+	return cp, nil
+}
@@ -0,0 +1,184 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+
+	"bsky.watch/redmine"
+)
+
+type issue struct {
+	id    int
+	start string
+	due   string
+	hours float32
+}
+
+type relation struct {
+	from, to int
+	typ      string
+	delay    string
+}
+
+func build(issues []issue, relations []relation) *Graph {
+	g := New()
+	for _, i := range issues {
+		g.AddIssue(&redmine.Issue{Id: i.id, StartDate: i.start, DueDate: i.due, EstimatedHours: i.hours})
+	}
+	for n, r := range relations {
+		g.AddRelation(redmine.IssueRelation{Id: n + 1, IssueId: r.from, IssueToId: r.to, RelationType: r.typ, Delay: r.delay})
+	}
+	return g
+}
+
+func TestCycles(t *testing.T) {
+	tests := []struct {
+		name      string
+		relations []relation
+		want      [][]int
+	}{
+		{
+			name:      "acyclic",
+			relations: []relation{{1, 2, "blocks", ""}, {2, 3, "precedes", ""}},
+		},
+		{
+			name:      "self loop",
+			relations: []relation{{1, 1, "blocks", ""}, {1, 2, "blocks", ""}},
+			want:      [][]int{{1}},
+		},
+		{
+			name:      "two cycles",
+			relations: []relation{{3, 1, "blocks", ""}, {1, 3, "precedes", ""}, {4, 5, "blocks", ""}, {5, 6, "blocks", ""}, {6, 4, "blocks", ""}, {3, 4, "blocks", ""}},
+			want:      [][]int{{1, 3}, {4, 5, 6}},
+		},
+		{
+			name:      "reverse forms",
+			relations: []relation{{1, 2, "blocks", ""}, {1, 2, "blocked", ""}},
+			want:      [][]int{{1, 2}},
+		},
+		{
+			name:      "non-dependency relations",
+			relations: []relation{{1, 2, "relates", ""}, {2, 1, "relates", ""}, {1, 1, "duplicates", ""}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := build(nil, tt.relations).Cycles()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Cycles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopologicalOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		relations []relation
+		want      []int
+		err       error
+	}{
+		{
+			name:      "ties by id",
+			relations: []relation{{5, 2, "blocks", ""}, {4, 2, "blocks", ""}, {1, 3, "relates", ""}},
+			want:      []int{1, 3, 4, 5, 2},
+		},
+		{
+			name:      "follows",
+			relations: []relation{{1, 2, "follows", ""}, {3, 2, "precedes", ""}},
+			want:      []int{3, 2, 1},
+		},
+		{
+			name:      "cycle",
+			relations: []relation{{1, 2, "blocks", ""}, {2, 1, "blocks", ""}},
+			err:       ErrCycle,
+		},
+		{
+			name:      "self loop",
+			relations: []relation{{1, 1, "precedes", ""}},
+			err:       ErrCycle,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := build(nil, tt.relations).TopologicalOrder()
+			if err != tt.err || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TopologicalOrder() = %v, %v, want %v, %v", got, err, tt.want, tt.err)
+			}
+		})
+	}
+}
+
+func TestCriticalPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		issues    []issue
+		relations []relation
+		want      []int
+		days      float64
+		err       error
+	}{
+		{
+			name:   "empty",
+			issues: nil,
+			days:   0,
+		},
+		{
+			name:   "single issue from dates",
+			issues: []issue{{id: 1, start: "2024-01-01", due: "2024-01-03"}},
+			want:   []int{1},
+			days:   3,
+		},
+		{
+			name:      "longest branch",
+			issues:    []issue{{id: 1, hours: 8}, {id: 2, hours: 16}, {id: 3, hours: 40}, {id: 4, hours: 8}},
+			relations: []relation{{1, 2, "blocks", ""}, {1, 3, "blocks", ""}, {2, 4, "blocks", ""}, {3, 4, "blocks", ""}},
+			want:      []int{1, 3, 4},
+			days:      7,
+		},
+		{
+			name:      "precedes delay",
+			issues:    []issue{{id: 1, hours: 8}, {id: 2, hours: 40}, {id: 3, hours: 8}},
+			relations: []relation{{1, 3, "precedes", "10"}, {2, 3, "blocks", ""}},
+			want:      []int{1, 3},
+			days:      12,
+		},
+		{
+			name:      "blocks ignore delay",
+			issues:    []issue{{id: 1, hours: 8}, {id: 2, hours: 8}},
+			relations: []relation{{1, 2, "blocks", "10"}},
+			want:      []int{1, 2},
+			days:      2,
+		},
+		{
+			name:      "cycle",
+			issues:    []issue{{id: 1}, {id: 2}},
+			relations: []relation{{1, 2, "precedes", ""}, {2, 1, "precedes", ""}},
+			err:       ErrCycle,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp, err := build(tt.issues, tt.relations).CriticalPath()
+			if err != tt.err {
+				t.Fatalf("CriticalPath() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if !reflect.DeepEqual(cp.Issues, tt.want) || cp.Days != tt.days {
+				t.Errorf("CriticalPath() = %v, %v days, want %v, %v days", cp.Issues, cp.Days, tt.want, tt.days)
+			}
+		})
+	}
+}
+
+func TestAddRelationDeduplicates(t *testing.T) {
+	g := New()
+	r := redmine.IssueRelation{Id: 7, IssueId: 1, IssueToId: 2, RelationType: "blocks"}
+	g.AddRelation(r)
+	g.AddRelation(r)
+	if len(g.Edges) != 1 {
+		t.Errorf("got %d edges, want 1", len(g.Edges))
+	}
+}
@@ -0,0 +1,226 @@
+package redmine
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+type groupRequest struct {
+	Group Group `json:"group"`
+}
+
+type groupResult struct {
+	Group Group `json:"group"`
+}
+
+type groupsResult struct {
+	Groups []Group `json:"groups"`
+}
+
+type Group struct {
+	Id           int             `json:"id"`
+	Name         string          `json:"name"`
+	Users        []IdName        `json:"users,omitempty"`
+	Memberships  []Membership    `json:"memberships,omitempty"`
+	CustomFields CustomFieldList `json:"custom_fields,omitempty"`
+
+	// Only used to create and update groups. Updating replaces the users.
+	UserIds []int `json:"user_ids,omitempty"`
+}
+
+// GroupInclude selects associated data returned with a group.
+type GroupInclude string
+
+const (
+	GroupIncludeUsers       GroupInclude = "users"
+	GroupIncludeMemberships GroupInclude = "memberships"
+)
+
+func (c *Client) Groups() ([]Group, error) {
+	req, err := c.NewRequest("GET", "/groups.json?"+c.getPaginationClause(), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	decoder := json.NewDecoder(res.Body)
+	var r groupsResult
+	if res.StatusCode != 200 {
+		err = errorFromResp(decoder, res.StatusCode)
+	} else {
+		err = decoder.Decode(&r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.Groups, nil
+}
+
+func (c *Client) Group(id int, include ...GroupInclude) (*Group, error) {
+	names := make([]string, len(include))
+	for i, inc := range include {
+		names[i] = string(inc)
+	}
+	uri := "/groups/" + strconv.Itoa(id) + ".json"
+	if len(names) > 0 {
+		uri += "?include=" + strings.Join(names, ",")
+	}
+	req, err := c.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return nil, errors.New("Not Found")
+	}
+	decoder := json.NewDecoder(res.Body)
+	var r groupResult
+	if res.StatusCode != 200 {
+		err = errorFromResp(decoder, res.StatusCode)
+	} else {
+		err = decoder.Decode(&r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r.Group, nil
+}
+
+func (c *Client) CreateGroup(group Group) (*Group, error) {
+	s, err := json.Marshal(groupRequest{Group: group})
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.NewRequest("POST", "/groups.json", strings.NewReader(string(s)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	decoder := json.NewDecoder(res.Body)
+	var r groupResult
+	if res.StatusCode != 201 {
+		err = errorFromResp(decoder, res.StatusCode)
+	} else {
+		err = decoder.Decode(&r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r.Group, nil
+}
+
+func (c *Client) UpdateGroup(group Group) error {
+	s, err := json.Marshal(groupRequest{Group: group})
+	if err != nil {
+		return err
+	}
+	req, err := c.NewRequest("PUT", "/groups/"+strconv.Itoa(group.Id)+".json", strings.NewReader(string(s)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return errors.New("Not Found")
+	}
+	if res.StatusCode/100 != 2 {
+		err = errorFromResp(json.NewDecoder(res.Body), res.StatusCode)
+	}
+	return err
+}
+
+func (c *Client) DeleteGroup(id int) error {
+	req, err := c.NewRequest("DELETE", "/groups/"+strconv.Itoa(id)+".json", strings.NewReader(""))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return errors.New("Not Found")
+	}
+	if res.StatusCode/100 != 2 {
+		err = errorFromResp(json.NewDecoder(res.Body), res.StatusCode)
+	}
+	return err
+}
+
+type groupUserRequest struct {
+	UserId int `json:"user_id"`
+}
+
+// AddUserToGroup adds the user to the group, giving them the group's
+// project memberships.
+func (c *Client) AddUserToGroup(groupId int, userId int) error {
+	s, err := json.Marshal(groupUserRequest{UserId: userId})
+	if err != nil {
+		return err
+	}
+	req, err := c.NewRequest("POST", "/groups/"+strconv.Itoa(groupId)+"/users.json", strings.NewReader(string(s)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return errors.New("Not Found")
+	}
+	if res.StatusCode/100 != 2 {
+		err = errorFromResp(json.NewDecoder(res.Body), res.StatusCode)
+	}
+	return err
+}
+
+// RemoveUserFromGroup removes the user from the group.
+func (c *Client) RemoveUserFromGroup(groupId int, userId int) error {
+	req, err := c.NewRequest("DELETE", "/groups/"+strconv.Itoa(groupId)+"/users/"+strconv.Itoa(userId)+".json", strings.NewReader(""))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return errors.New("Not Found")
+	}
+	if res.StatusCode/100 != 2 {
+		err = errorFromResp(json.NewDecoder(res.Body), res.StatusCode)
+	}
+	return err
+}
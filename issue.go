@@ -41,37 +41,43 @@ type Journal struct {
 }
 
 type Issue struct {
-	Id             int            `json:"id"`
-	Subject        string         `json:"subject"`
-	Description    string         `json:"description"`
-	ProjectId      int            `json:"project_id"`
-	Project        *IdName        `json:"project,omitempty"`
-	TrackerId      int            `json:"tracker_id"`
-	Tracker        *IdName        `json:"tracker,omitempty"`
-	ParentId       int            `json:"parent_issue_id,omitempty"`
-	Parent         *Id            `json:"parent,omitempty"`
-	StatusId       int            `json:"status_id"`
-	Status         *IdName        `json:"status,omitempty"`
-	PriorityId     int            `json:"priority_id,omitempty"`
-	Priority       *IdName        `json:"priority,omitempty"`
-	Author         *IdName        `json:"author,omitempty"`
-	FixedVersion   *IdName        `json:"fixed_version,omitempty"`
-	AssignedTo     *IdName        `json:"assigned_to"`
-	AssignedToId   int            `json:"assigned_to_id,omitempty"`
-	Category       *IdName        `json:"category"`
-	CategoryId     int            `json:"category_id,omitempty"`
-	Notes          string         `json:"notes"`
-	StatusDate     string         `json:"status_date"`
-	CreatedOn      string         `json:"created_on"`
-	UpdatedOn      string         `json:"updated_on"`
-	StartDate      string         `json:"start_date"`
-	DueDate        string         `json:"due_date"`
-	ClosedOn       string         `json:"closed_on"`
-	CustomFields   []*CustomField `json:"custom_fields,omitempty"`
-	Uploads        []*Upload      `json:"uploads,omitempty"`
-	DoneRatio      float32        `json:"done_ratio,omitempty"`
-	EstimatedHours float32        `json:"estimated_hours,omitempty"`
-	Journals       []*Journal     `json:"journals,omitempty"`
+	Id             int              `json:"id"`
+	Subject        string           `json:"subject"`
+	Description    string           `json:"description"`
+	ProjectId      int              `json:"project_id"`
+	Project        *IdName          `json:"project,omitempty"`
+	TrackerId      int              `json:"tracker_id"`
+	Tracker        *IdName          `json:"tracker,omitempty"`
+	ParentId       int              `json:"parent_issue_id,omitempty"`
+	Parent         *Id              `json:"parent,omitempty"`
+	StatusId       int              `json:"status_id"`
+	Status         *IdName          `json:"status,omitempty"`
+	PriorityId     int              `json:"priority_id,omitempty"`
+	Priority       *IdName          `json:"priority,omitempty"`
+	Author         *IdName          `json:"author,omitempty"`
+	FixedVersion   *IdName          `json:"fixed_version,omitempty"`
+	FixedVersionId int              `json:"fixed_version_id,omitempty"`
+	AssignedTo     *IdName          `json:"assigned_to"`
+	AssignedToId   int              `json:"assigned_to_id,omitempty"`
+	Category       *IdName          `json:"category"`
+	CategoryId     int              `json:"category_id,omitempty"`
+	Notes          string           `json:"notes"`
+	StatusDate     string           `json:"status_date"`
+	CreatedOn      string           `json:"created_on"`
+	UpdatedOn      string           `json:"updated_on"`
+	StartDate      string           `json:"start_date"`
+	DueDate        string           `json:"due_date"`
+	ClosedOn       string           `json:"closed_on"`
+	CustomFields   CustomFieldList  `json:"custom_fields,omitempty"`
+	Uploads        []*Upload        `json:"uploads,omitempty"`
+	Attachments    []*Attachment    `json:"attachments,omitempty"`
+	DoneRatio      float32          `json:"done_ratio,omitempty"`
+	EstimatedHours float32          `json:"estimated_hours,omitempty"`
+	SpentHours     float32          `json:"spent_hours,omitempty"`
+	Journals       []*Journal       `json:"journals,omitempty"`
+	Relations      []*IssueRelation `json:"relations,omitempty"`
+	Watchers       []*IdName        `json:"watchers,omitempty"`
+	WatcherUserIds []int            `json:"watcher_user_ids,omitempty"`
 }
 
 type IssueFilter struct {
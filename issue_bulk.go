@@ -0,0 +1,149 @@
+package redmine
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// IssuePatch is a partial update of an issue, keyed by the attribute names
+// of the Redmine API ("status_id", "fixed_version_id", "notes", ...).
+// Unlike UpdateIssue, attributes that are not set are left unchanged.
+type IssuePatch map[string]interface{}
+
+type issuePatchRequest struct {
+	Issue IssuePatch `json:"issue"`
+}
+
+// PatchIssue applies a partial update to the issue with the given id.
+func (c *Client) PatchIssue(id int, patch IssuePatch) error {
+	s, err := json.Marshal(issuePatchRequest{Issue: patch})
+	if err != nil {
+		return err
+	}
+	req, err := c.NewRequest("PUT", "/issues/"+strconv.Itoa(id)+".json", strings.NewReader(string(s)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return errors.New("Not Found")
+	}
+	if res.StatusCode/100 != 2 {
+		err = errorFromResp(json.NewDecoder(res.Body), res.StatusCode)
+	}
+	return err
+}
+
+// IssueSelector selects the issues of a bulk operation. Exactly one of its
+// fields should be set.
+type IssueSelector struct {
+	Ids    []int
+	Filter *IssueFilter
+	// QueryId selects the issues of a global query. Project queries need
+	// a Filter with the project and a "query_id" extra filter.
+	QueryId int
+}
+
+// SelectIssues returns the ids of the issues matching selector.
+func (c *Client) SelectIssues(selector IssueSelector) ([]int, error) {
+	if len(selector.Ids) > 0 {
+		return selector.Ids, nil
+	}
+	var issues []Issue
+	var err error
+	switch {
+	case selector.Filter != nil:
+		issues, err = c.IssuesByFilter(selector.Filter)
+	case selector.QueryId != 0:
+		issues, err = c.IssuesByQuery(selector.QueryId)
+	default:
+		return nil, errors.New("Empty selector")
+	}
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, len(issues))
+	for i, issue := range issues {
+		ids[i] = issue.Id
+	}
+	return ids, nil
+}
+
+// BulkOptions controls a bulk operation.
+type BulkOptions struct {
+	// Concurrency is the number of requests sent at once, 4 if not set.
+	Concurrency int
+	// Notes are added to every issue changed.
+	Notes string
+	// DryRun selects the issues but changes nothing.
+	DryRun bool
+}
+
+// BulkResult is the outcome of a bulk operation for a single issue.
+// Err is nil if the issue was updated.
+type BulkResult struct {
+	IssueId int
+	Err     error
+}
+
+// BulkUpdate applies patch to every selected issue. The returned error only
+// reports a failure to select the issues; failures to update individual
+// issues are reported in the results, in selection order.
+// opts may be nil.
+func (c *Client) BulkUpdate(selector IssueSelector, patch IssuePatch, opts *BulkOptions) ([]BulkResult, error) {
+	if opts == nil {
+		opts = &BulkOptions{}
+	}
+	ids, err := c.SelectIssues(selector)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]BulkResult, len(ids))
+	for i, id := range ids {
+		results[i].IssueId = id
+	}
+	if opts.DryRun {
+		return results, nil
+	}
+
+	p := make(IssuePatch, len(patch)+1)
+	for k, v := range patch {
+		p[k] = v
+	}
+	if opts.Notes != "" {
+		p["notes"] = opts.Notes
+	}
+
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(r *BulkResult) {
+			defer wg.Done()
+			r.Err = c.PatchIssue(r.IssueId, p)
+			<-sem
+		}(&results[i])
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// BulkTransition moves every selected issue to the given status.
+// See BulkUpdate.
+func (c *Client) BulkTransition(selector IssueSelector, statusId int, opts *BulkOptions) ([]BulkResult, error) {
+	return c.BulkUpdate(selector, IssuePatch{"status_id": statusId}, opts)
+}
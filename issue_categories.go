@@ -21,10 +21,11 @@ type issueCategoryRequest struct {
 }
 
 type IssueCategory struct {
-	Id         int    `json:"id"`
-	Project    IdName `json:"project"`
-	Name       string `json:"name"`
-	AssignedTo IdName `json:"assigned_to"`
+	Id           int    `json:"id"`
+	Project      IdName `json:"project"`
+	Name         string `json:"name"`
+	AssignedTo   IdName `json:"assigned_to"`
+	AssignedToId int    `json:"assigned_to_id,omitempty"`
 }
 
 func (c *Client) IssueCategories(projectId int) ([]IssueCategory, error) {
@@ -96,7 +97,7 @@ func (c *Client) CreateIssueCategory(issueCategory IssueCategory) (*IssueCategor
 	if err != nil {
 		return nil, err
 	}
-	req, err := c.NewRequest("POST", "/issue_categories.json", strings.NewReader(string(s)))
+	req, err := c.NewRequest("POST", "/projects/"+strconv.Itoa(issueCategory.Project.Id)+"/issue_categories.json", strings.NewReader(string(s)))
 	if err != nil {
 		return nil, err
 	}
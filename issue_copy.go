@@ -0,0 +1,388 @@
+package redmine
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CopyOptions controls CopyIssue and MoveIssue.
+type CopyOptions struct {
+	// ProjectId is the target project.
+	ProjectId int
+
+	// Subtasks copies or moves the descendants of the issue too.
+	Subtasks bool
+	// Attachments downloads the attachments and uploads them to the copy.
+	Attachments bool
+	// Watchers adds the watchers of the original to the copy.
+	Watchers bool
+	// Relations copies the relations of the original to the copy. Relations
+	// between copied issues are recreated between the copies.
+	Relations bool
+	// Link relates each original to its copy with a "copied_to" relation.
+	Link bool
+
+	// TrackerMap maps tracker ids of the source to tracker ids of the
+	// target. Trackers that are neither mapped nor enabled in the target
+	// project are replaced by its first tracker.
+	TrackerMap map[int]int
+}
+
+// CopyChange reports a field changed because it does not exist in the
+// target project. To is empty when the field was dropped. Custom fields are
+// named "cf:" followed by their name.
+type CopyChange struct {
+	IssueId int
+	Field   string
+	From    string
+	To      string
+}
+
+// CopyReport is the outcome of CopyIssue and MoveIssue.
+type CopyReport struct {
+	// Issues maps the ids of the original issues to the ids of the copies,
+	// or to themselves when moved.
+	Issues  map[int]int
+	Changes []CopyChange
+}
+
+// projectFields holds what issues may refer to in the target project.
+type projectFields struct {
+	trackers   []IdName
+	categories []IssueCategory
+	versions   []Version
+	members    map[int]bool
+	// customFields holds the issue custom fields enabled in the project,
+	// and cfTrackers the trackers of each, if the definitions are visible.
+	customFields map[int]bool
+	cfTrackers   map[int]map[int]bool
+}
+
+func (c *Client) loadProjectFields(projectId int) (*projectFields, error) {
+	var f projectFields
+	var err error
+	project, err := c.Project(projectId, ProjectIncludeTrackers, ProjectIncludeIssueCustomFields)
+	if err != nil {
+		return nil, err
+	}
+	f.trackers = project.Trackers
+	f.customFields = map[int]bool{}
+	for _, cf := range project.IssueCustomFields {
+		f.customFields[cf.Id] = true
+	}
+	// Only administrators see the definitions; trackers are not checked
+	// otherwise.
+	if definitions, err := c.CustomFields(); err == nil {
+		f.cfTrackers = map[int]map[int]bool{}
+		for _, d := range definitions {
+			if d.CustomizedType != "issue" {
+				continue
+			}
+			f.cfTrackers[d.Id] = map[int]bool{}
+			for _, t := range d.Trackers {
+				f.cfTrackers[d.Id][t.Id] = true
+			}
+		}
+	}
+	if f.categories, err = c.IssueCategories(projectId); err != nil {
+		return nil, err
+	}
+	if f.versions, err = c.Versions(projectId); err != nil {
+		return nil, err
+	}
+	memberships, err := c.allMemberships(projectId)
+	if err != nil {
+		return nil, err
+	}
+	f.members = map[int]bool{}
+	for _, m := range memberships {
+		if m.Group != nil {
+			f.members[m.Group.Id] = true
+		} else if m.User.Id != 0 {
+			f.members[m.User.Id] = true
+		}
+	}
+	return &f, nil
+}
+
+// remap computes the target project values of the issue's tracker,
+// category, version and assignee, recording the changes in report.
+func (f *projectFields) remap(issue *Issue, opts *CopyOptions, report *CopyReport) (trackerId, categoryId, versionId, assignedToId int) {
+	change := func(field, from, to string) {
+		report.Changes = append(report.Changes, CopyChange{IssueId: issue.Id, Field: field, From: from, To: to})
+	}
+
+	if issue.Tracker != nil {
+		trackerId = issue.Tracker.Id
+		if mapped, ok := opts.TrackerMap[trackerId]; ok {
+			trackerId = mapped
+		}
+		found := false
+		for _, t := range f.trackers {
+			found = found || t.Id == trackerId
+		}
+		if !found && len(f.trackers) > 0 {
+			trackerId = f.trackers[0].Id
+		}
+		if trackerId != issue.Tracker.Id {
+			to := strconv.Itoa(trackerId)
+			for _, t := range f.trackers {
+				if t.Id == trackerId {
+					to = t.Name
+				}
+			}
+			change("tracker", issue.Tracker.Name, to)
+		}
+	}
+	if issue.Category != nil {
+		for _, cat := range f.categories {
+			if strings.EqualFold(cat.Name, issue.Category.Name) {
+				categoryId = cat.Id
+			}
+		}
+		if categoryId == 0 {
+			change("category", issue.Category.Name, "")
+		}
+	}
+	if issue.FixedVersion != nil {
+		for _, v := range f.versions {
+			if v.Id == issue.FixedVersion.Id {
+				versionId = v.Id
+			}
+		}
+		if versionId == 0 {
+			for _, v := range f.versions {
+				if strings.EqualFold(v.Name, issue.FixedVersion.Name) {
+					versionId = v.Id
+				}
+			}
+		}
+		if versionId == 0 {
+			change("fixed_version", issue.FixedVersion.Name, "")
+		}
+	}
+	if issue.AssignedTo != nil {
+		if f.members[issue.AssignedTo.Id] {
+			assignedToId = issue.AssignedTo.Id
+		} else {
+			change("assigned_to", issue.AssignedTo.Name, "")
+		}
+	}
+	return
+}
+
+// keptCustomFields returns the custom fields of the issue enabled for the
+// target project and tracker, recording the others with a value in report.
+func (f *projectFields) keptCustomFields(issue *Issue, trackerId int, report *CopyReport) CustomFieldList {
+	var kept CustomFieldList
+	for _, cf := range issue.CustomFields {
+		enabled := f.customFields[cf.Id]
+		if trackers, ok := f.cfTrackers[cf.Id]; ok && !trackers[trackerId] {
+			enabled = false
+		}
+		if enabled {
+			kept = append(kept, cf)
+		} else if values := cf.Values(); len(values) > 0 {
+			report.Changes = append(report.Changes, CopyChange{IssueId: issue.Id, Field: "cf:" + cf.Name, From: strings.Join(values, ", ")})
+		}
+	}
+	return kept
+}
+
+// subtree returns the ids of the issue and, if requested, of its
+// descendants, parents first.
+func (c *Client) subtree(id int, subtasks bool) ([]int, error) {
+	if !subtasks {
+		return []int{id}, nil
+	}
+	root, err := c.IssueTree(id)
+	if err != nil {
+		return nil, err
+	}
+	var ids []int
+	root.Walk(func(node *IssueNode, depth int) {
+		ids = append(ids, node.Issue.Id)
+	})
+	return ids, nil
+}
+
+// copyAttachment streams the content of the attachment into a new upload.
+func (c *Client) copyAttachment(a *Attachment) (*Upload, error) {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(c.DownloadAttachment(a.Id, pw))
+	}()
+	upload, err := c.UploadReader(pr, a.Filename, a.Filesize, &UploadOptions{ContentType: a.ContentType})
+	// Stops the download if the upload failed early.
+	pr.CloseWithError(errors.New("Upload failed"))
+	if err != nil {
+		return nil, err
+	}
+	upload.Description = a.Description
+	return upload, nil
+}
+
+// CopyIssue copies the issue with the given id to opts.ProjectId. Fields
+// referring to trackers, categories, versions, users or custom fields not
+// available in the target project are remapped or dropped, as listed in the
+// report. The copy of the issue itself has no parent.
+func (c *Client) CopyIssue(id int, opts CopyOptions) (*CopyReport, error) {
+	fields, err := c.loadProjectFields(opts.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := c.subtree(id, opts.Subtasks)
+	if err != nil {
+		return nil, err
+	}
+	include := []string{"relations"}
+	if opts.Attachments {
+		include = append(include, "attachments")
+	}
+	if opts.Watchers {
+		include = append(include, "watchers")
+	}
+
+	report := &CopyReport{Issues: map[int]int{}}
+	var originals []*Issue
+	for _, sourceId := range ids {
+		source, err := getOneIssue(c, sourceId, map[string]string{"include": strings.Join(include, ",")})
+		if err != nil {
+			return report, err
+		}
+		originals = append(originals, source)
+
+		dup := Issue{
+			ProjectId:      opts.ProjectId,
+			Subject:        source.Subject,
+			Description:    source.Description,
+			StartDate:      source.StartDate,
+			DueDate:        source.DueDate,
+			DoneRatio:      source.DoneRatio,
+			EstimatedHours: source.EstimatedHours,
+		}
+		dup.TrackerId, dup.CategoryId, dup.FixedVersionId, dup.AssignedToId = fields.remap(source, &opts, report)
+		dup.CustomFields = fields.keptCustomFields(source, dup.TrackerId, report)
+		if source.Status != nil {
+			dup.StatusId = source.Status.Id
+		}
+		if source.Priority != nil {
+			dup.PriorityId = source.Priority.Id
+		}
+		// The copy of the root is not a subtask of the original's parent.
+		if source.Parent != nil {
+			if parentId, ok := report.Issues[source.Parent.Id]; ok {
+				dup.ParentId = parentId
+				dup.Parent = &Id{parentId}
+			} else {
+				report.Changes = append(report.Changes, CopyChange{IssueId: source.Id, Field: "parent", From: "#" + strconv.Itoa(source.Parent.Id)})
+			}
+		}
+		if opts.Watchers {
+			for _, w := range source.Watchers {
+				dup.WatcherUserIds = append(dup.WatcherUserIds, w.Id)
+			}
+		}
+		if opts.Attachments {
+			for _, a := range source.Attachments {
+				upload, err := c.copyAttachment(a)
+				if err != nil {
+					return report, err
+				}
+				dup.Uploads = append(dup.Uploads, upload)
+			}
+		}
+
+		created, err := c.CreateIssue(dup)
+		if err != nil {
+			return report, err
+		}
+		report.Issues[sourceId] = created.Id
+	}
+
+	for _, source := range originals {
+		copyId := report.Issues[source.Id]
+		if opts.Link {
+			_, err := c.CreateIssueRelation(IssueRelation{IssueId: source.Id, IssueToId: copyId, RelationType: "copied_to"})
+			if err != nil {
+				return report, err
+			}
+		}
+		if !opts.Relations {
+			continue
+		}
+		for _, r := range source.Relations {
+			if r.RelationType == "copied_to" || r.RelationType == "copied_from" {
+				continue
+			}
+			from, to := r.IssueId, r.IssueToId
+			if copied, ok := report.Issues[from]; ok {
+				from = copied
+			}
+			if copied, ok := report.Issues[to]; ok {
+				to = copied
+			}
+			// Relations between two copied issues are seen from both ends;
+			// create them once.
+			_, fromCopied := report.Issues[r.IssueId]
+			_, toCopied := report.Issues[r.IssueToId]
+			if fromCopied && toCopied && source.Id != r.IssueId {
+				continue
+			}
+			_, err := c.CreateIssueRelation(IssueRelation{IssueId: from, IssueToId: to, RelationType: r.RelationType, Delay: r.Delay})
+			if err != nil {
+				return report, err
+			}
+		}
+	}
+	return report, nil
+}
+
+// MoveIssue moves the issue with the given id to opts.ProjectId, remapping
+// or dropping the fields not available there. Only ProjectId, Subtasks and
+// TrackerMap of opts are used.
+func (c *Client) MoveIssue(id int, opts CopyOptions) (*CopyReport, error) {
+	fields, err := c.loadProjectFields(opts.ProjectId)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := c.subtree(id, opts.Subtasks)
+	if err != nil {
+		return nil, err
+	}
+	report := &CopyReport{Issues: map[int]int{}}
+	// Leaves go first, so that no moved parent has children left behind.
+	for i := len(ids) - 1; i >= 0; i-- {
+		issueId := ids[i]
+		issue, err := c.Issue(issueId)
+		if err != nil {
+			return report, err
+		}
+		trackerId, categoryId, versionId, assignedToId := fields.remap(issue, &opts, report)
+		patch := IssuePatch{
+			"project_id":       opts.ProjectId,
+			"category_id":      "",
+			"fixed_version_id": "",
+			"assigned_to_id":   "",
+		}
+		if trackerId != 0 {
+			patch["tracker_id"] = trackerId
+		}
+		if categoryId != 0 {
+			patch["category_id"] = categoryId
+		}
+		if versionId != 0 {
+			patch["fixed_version_id"] = versionId
+		}
+		if assignedToId != 0 {
+			patch["assigned_to_id"] = assignedToId
+		}
+		if err := c.PatchIssue(issueId, patch); err != nil {
+			return report, err
+		}
+		report.Issues[issueId] = issueId
+	}
+	return report, nil
+}
@@ -26,7 +26,61 @@ type CustomFieldDefinition struct {
 	PossibleValues []CustomFieldPossibleValue `json:"possible_values"`
 	Trackers       []IdName                   `json:"trackers"`
 	Roles          []IdName                   `json:"roles"`
+	TrackerIds     []int                      `json:"tracker_ids,omitempty"`
+	RoleIds        []int                      `json:"role_ids,omitempty"`
 	EditTagStyle   *string                    `json:"edit_tag_style,omitempty"`
+
+	// given holds the attributes set in the JSON the definition was
+	// decoded from, nil if it was not decoded.
+	given map[string]bool
+}
+
+func (d *CustomFieldDefinition) UnmarshalJSON(b []byte) error {
+	type definition CustomFieldDefinition
+	var attrs map[string]json.RawMessage
+	if err := json.Unmarshal(b, &attrs); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, (*definition)(d)); err != nil {
+		return err
+	}
+	d.given = make(map[string]bool, len(attrs))
+	for attr := range attrs {
+		d.given[attr] = true
+	}
+	return nil
+}
+
+// MarshalJSON leaves TrackerIds and RoleIds out when nil, and sends them
+// when empty, which clears them.
+func (d CustomFieldDefinition) MarshalJSON() ([]byte, error) {
+	type definition CustomFieldDefinition
+	wire := struct {
+		definition
+		TrackerIds *[]int `json:"tracker_ids,omitempty"`
+		RoleIds    *[]int `json:"role_ids,omitempty"`
+	}{definition: definition(d)}
+	if d.TrackerIds != nil {
+		wire.TrackerIds = &d.TrackerIds
+	}
+	if d.RoleIds != nil {
+		wire.RoleIds = &d.RoleIds
+	}
+	return json.Marshal(wire)
+}
+
+// has reports whether the attribute is set in the definition. All of them
+// are, unless the definition was decoded from JSON lacking some.
+func (d *CustomFieldDefinition) has(attrs ...string) bool {
+	if d.given == nil {
+		return true
+	}
+	for _, attr := range attrs {
+		if d.given[attr] {
+			return true
+		}
+	}
+	return false
 }
 
 // Redmine (as of 6.0.2) has very weird handling of possible_values:
@@ -37,14 +91,23 @@ type CustomFieldPossibleValue struct {
 	Value string
 }
 
+// UnmarshalJSON accepts the objects returned by Redmine as well as the
+// plain strings it expects, as written by MarshalJSON.
 func (v *CustomFieldPossibleValue) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &v.Value); err == nil {
+		return nil
+	}
 	var input struct {
 		Label string `json:"label"`
+		Value string `json:"value"`
 	}
 	if err := json.Unmarshal(b, &input); err != nil {
 		return err
 	}
 	v.Value = input.Label
+	if v.Value == "" {
+		v.Value = input.Value
+	}
 	return nil
 }
 
@@ -125,3 +188,73 @@ func (c *Client) UpdateCustomField(cf CustomFieldDefinition) error {
 	}
 	return err
 }
+
+// ErrCustomFieldsReadOnly is returned by CreateCustomField and
+// DeleteCustomField when the server does not allow managing custom fields
+// through the API, as stock Redmine does not.
+var ErrCustomFieldsReadOnly = errors.New("Custom fields cannot be managed through the API on this server")
+
+type customFieldResult struct {
+	CustomField CustomFieldDefinition `json:"custom_field"`
+}
+
+// CreateCustomField creates a custom field. This requires a Redmine version
+// or plugin supporting it; ErrCustomFieldsReadOnly is returned otherwise.
+func (c *Client) CreateCustomField(cf CustomFieldDefinition) (*CustomFieldDefinition, error) {
+	b, err := json.Marshal(&customFieldRequest{CustomField: cf})
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.NewRequest("POST", "/custom_fields.json", bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 404, 405, 406:
+		return nil, ErrCustomFieldsReadOnly
+	}
+	decoder := json.NewDecoder(res.Body)
+	var r customFieldResult
+	if res.StatusCode != 201 && res.StatusCode != 200 {
+		err = errorFromResp(decoder, res.StatusCode)
+	} else {
+		err = decoder.Decode(&r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r.CustomField, nil
+}
+
+// DeleteCustomField deletes the custom field with the given id, and all its
+// values. See CreateCustomField.
+func (c *Client) DeleteCustomField(id int) error {
+	req, err := c.NewRequest("DELETE", fmt.Sprintf("/custom_fields/%d.json", id), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case 404:
+		return errors.New("Not Found")
+	case 405, 406:
+		return ErrCustomFieldsReadOnly
+	}
+	if res.StatusCode/100 != 2 {
+		err = errorFromResp(json.NewDecoder(res.Body), res.StatusCode)
+	}
+	return err
+}
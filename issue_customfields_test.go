@@ -0,0 +1,105 @@
+package redmine
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCustomFieldDefinitionRoundTrip(t *testing.T) {
+	// As returned by Redmine.
+	var defs []CustomFieldDefinition
+	err := json.Unmarshal([]byte(`[{"id": 3, "name": "Severity", "customized_type": "issue", "field_format": "list",
+		"multiple": true, "visible": true,
+		"possible_values": [{"value": "low", "label": "low"}, {"value": "high", "label": "high"}],
+		"trackers": [{"id": 1, "name": "Bug"}], "roles": []}]`), &defs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// As written by godmine cf l and read back by godmine cf apply.
+	b, err := json.Marshal(defs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []CustomFieldDefinition
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("decoding %s: %v", b, err)
+	}
+	if got, want := possibleValues(&decoded[0]), []string{"low", "high"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("possible values = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(decoded[0].Trackers, defs[0].Trackers) || !decoded[0].Multiple || !decoded[0].Visible {
+		t.Errorf("decoded %+v, want %+v", decoded[0], defs[0])
+	}
+	if fields := diffCustomField(&decoded[0], &defs[0]); len(fields) != 0 {
+		t.Errorf("round trip changed %v", fields)
+	}
+}
+
+func TestCustomFieldPossibleValueUnmarshal(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`"plain"`, "plain"},
+		{`{"value": "v", "label": "Label"}`, "Label"},
+		{`{"value": "v"}`, "v"},
+	}
+	for _, tt := range tests {
+		var v CustomFieldPossibleValue
+		if err := json.Unmarshal([]byte(tt.in), &v); err != nil || v.Value != tt.want {
+			t.Errorf("Unmarshal(%s) = %q, %v, want %q", tt.in, v.Value, err, tt.want)
+		}
+	}
+}
+
+func TestCustomFieldDefinitionMarshalIds(t *testing.T) {
+	tests := []struct {
+		name string
+		def  CustomFieldDefinition
+		want []string
+		not  []string
+	}{
+		{"nil ids", CustomFieldDefinition{Name: "a"}, nil, []string{`"tracker_ids"`, `"role_ids"`}},
+		{"empty ids", CustomFieldDefinition{Name: "a", TrackerIds: []int{}, RoleIds: []int{}}, []string{`"tracker_ids":[]`, `"role_ids":[]`}, nil},
+		{"ids", CustomFieldDefinition{Name: "a", TrackerIds: []int{1, 2}}, []string{`"tracker_ids":[1,2]`}, []string{`"role_ids"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := json.Marshal(customFieldRequest{CustomField: tt.def})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(string(b), s) {
+					t.Errorf("%s lacks %s", b, s)
+				}
+			}
+			for _, s := range tt.not {
+				if strings.Contains(string(b), s) {
+					t.Errorf("%s has %s", b, s)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanCustomFieldsClearsTrackers(t *testing.T) {
+	current := []CustomFieldDefinition{{Id: 3, Name: "Severity", CustomizedType: "issue", FieldFormat: "string", Trackers: []IdName{{Id: 1, Name: "Bug"}}}}
+	var desired []CustomFieldDefinition
+	if err := json.Unmarshal([]byte(`[{"name": "Severity", "customized_type": "issue", "trackers": []}]`), &desired); err != nil {
+		t.Fatal(err)
+	}
+	changes, err := PlanCustomFields(current, desired, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || !reflect.DeepEqual(changes[0].Fields, []string{"trackers"}) {
+		t.Fatalf("changes = %v", changes)
+	}
+	b, _ := json.Marshal(customFieldRequest{CustomField: changes[0].Definition})
+	if !strings.Contains(string(b), `"tracker_ids":[]`) {
+		t.Errorf("update %s does not clear the trackers", b)
+	}
+}
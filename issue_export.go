@@ -0,0 +1,219 @@
+package redmine
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DefaultExportColumns are the columns exported when none are given.
+var DefaultExportColumns = []string{"id", "tracker", "status", "priority", "subject", "assigned_to", "updated_on"}
+
+// ExportOptions selects what is exported.
+//
+// Columns are attribute names such as "id", "status", "assigned_to" or
+// "estimated_hours", "relations", or "cf:<name>" for custom fields.
+// Relations are only known for issues fetched with include=relations.
+type ExportOptions struct {
+	Columns []string
+
+	// IncludeJournals adds the journals of the issues, fetched with
+	// include=journals, as a "journals" field in JSON Lines and as a
+	// "journals" column of notes in CSV and Markdown.
+	IncludeJournals bool
+}
+
+var reverseRelationTypes = map[string]string{
+	"relates":     "relates",
+	"duplicates":  "duplicated",
+	"duplicated":  "duplicates",
+	"blocks":      "blocked",
+	"blocked":     "blocks",
+	"precedes":    "follows",
+	"follows":     "precedes",
+	"copied_to":   "copied_from",
+	"copied_from": "copied_to",
+}
+
+func nameOf(v *IdName) string {
+	if v == nil {
+		return ""
+	}
+	return v.Name
+}
+
+var issueColumns = map[string]func(issue *Issue) string{
+	"id":              func(i *Issue) string { return strconv.Itoa(i.Id) },
+	"project":         func(i *Issue) string { return nameOf(i.Project) },
+	"tracker":         func(i *Issue) string { return nameOf(i.Tracker) },
+	"status":          func(i *Issue) string { return nameOf(i.Status) },
+	"priority":        func(i *Issue) string { return nameOf(i.Priority) },
+	"author":          func(i *Issue) string { return nameOf(i.Author) },
+	"assigned_to":     func(i *Issue) string { return nameOf(i.AssignedTo) },
+	"category":        func(i *Issue) string { return nameOf(i.Category) },
+	"fixed_version":   func(i *Issue) string { return nameOf(i.FixedVersion) },
+	"subject":         func(i *Issue) string { return i.Subject },
+	"description":     func(i *Issue) string { return i.Description },
+	"start_date":      func(i *Issue) string { return i.StartDate },
+	"due_date":        func(i *Issue) string { return i.DueDate },
+	"done_ratio":      func(i *Issue) string { return strconv.Itoa(int(i.DoneRatio)) },
+	"estimated_hours": func(i *Issue) string { return floatString(i.EstimatedHours) },
+	"spent_hours":     func(i *Issue) string { return floatString(i.SpentHours) },
+	"created_on":      func(i *Issue) string { return i.CreatedOn },
+	"updated_on":      func(i *Issue) string { return i.UpdatedOn },
+	"closed_on":       func(i *Issue) string { return i.ClosedOn },
+	"parent": func(i *Issue) string {
+		if i.Parent == nil {
+			return ""
+		}
+		return strconv.Itoa(i.Parent.Id)
+	},
+	"relations": func(i *Issue) string {
+		var relations []string
+		for _, r := range i.Relations {
+			if r.IssueId == i.Id {
+				relations = append(relations, r.RelationType+" #"+strconv.Itoa(r.IssueToId))
+			} else {
+				relations = append(relations, reverseRelationTypes[r.RelationType]+" #"+strconv.Itoa(r.IssueId))
+			}
+		}
+		return strings.Join(relations, ", ")
+	},
+}
+
+// IssueColumn returns the value of the named column for the issue, see
+// ExportOptions.
+func IssueColumn(issue *Issue, column string) (string, error) {
+	if strings.HasPrefix(column, "cf:") {
+		for _, cf := range issue.CustomFields {
+			if cf.Name == column[3:] {
+				return strings.Join(customFieldValues(cf.Value), ", "), nil
+			}
+		}
+		return "", nil
+	}
+	f, ok := issueColumns[column]
+	if !ok {
+		return "", errors.New("Unknown column: " + column)
+	}
+	return f(issue), nil
+}
+
+func journalNotes(issue *Issue) string {
+	var notes []string
+	for _, j := range issue.Journals {
+		if j.Notes != "" {
+			notes = append(notes, j.Notes)
+		}
+	}
+	return strings.Join(notes, "\n\n")
+}
+
+func (opts *ExportOptions) columns() ([]string, error) {
+	columns := DefaultExportColumns
+	if opts != nil && len(opts.Columns) > 0 {
+		columns = opts.Columns
+	}
+	for _, c := range columns {
+		if _, ok := issueColumns[c]; !ok && !strings.HasPrefix(c, "cf:") {
+			return nil, errors.New("Unknown column: " + c)
+		}
+	}
+	return columns, nil
+}
+
+func (opts *ExportOptions) rows(issues []Issue) ([]string, [][]string, error) {
+	columns, err := opts.columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	header := append([]string(nil), columns...)
+	journals := opts != nil && opts.IncludeJournals
+	if journals {
+		header = append(header, "journals")
+	}
+	rows := make([][]string, len(issues))
+	for i := range issues {
+		row := make([]string, 0, len(header))
+		for _, c := range columns {
+			v, _ := IssueColumn(&issues[i], c)
+			row = append(row, v)
+		}
+		if journals {
+			row = append(row, journalNotes(&issues[i]))
+		}
+		rows[i] = row
+	}
+	return header, rows, nil
+}
+
+// ExportIssuesCSV writes issues as CSV with a header row. opts may be nil.
+func ExportIssuesCSV(w io.Writer, issues []Issue, opts *ExportOptions) error {
+	header, rows, err := opts.rows(issues)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	cw.WriteAll(rows)
+	return cw.Error()
+}
+
+// ExportIssuesJSONL writes one JSON object per issue and line, keyed by
+// column. opts may be nil.
+func ExportIssuesJSONL(w io.Writer, issues []Issue, opts *ExportOptions) error {
+	columns, err := opts.columns()
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	for i := range issues {
+		obj := make(map[string]interface{}, len(columns)+1)
+		for _, c := range columns {
+			obj[c], _ = IssueColumn(&issues[i], c)
+		}
+		if opts != nil && opts.IncludeJournals {
+			obj["journals"] = issues[i].Journals
+		}
+		if err := encoder.Encode(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func markdownCell(s string) string {
+	s = strings.Replace(s, "|", `\|`, -1)
+	s = strings.Replace(s, "\r\n", "<br>", -1)
+	return strings.Replace(s, "\n", "<br>", -1)
+}
+
+// ExportIssuesMarkdown writes issues as a Markdown table. opts may be nil.
+func ExportIssuesMarkdown(w io.Writer, issues []Issue, opts *ExportOptions) error {
+	header, rows, err := opts.rows(issues)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("|")
+		for _, c := range cells {
+			b.WriteString(" " + markdownCell(c) + " |")
+		}
+		b.WriteString("\n")
+	}
+	writeRow(header)
+	b.WriteString("|")
+	for range header {
+		b.WriteString(" --- |")
+	}
+	b.WriteString("\n")
+	for _, row := range rows {
+		writeRow(row)
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,435 @@
+package redmine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// IssueState holds the values of an issue's fields at a point in time,
+// as they are named and formatted in journal details.
+type IssueState struct {
+	// Attributes maps attribute names ("status_id", "subject", ...) to values.
+	Attributes map[string]string
+	// CustomFields maps custom field ids to their values. Fields that are
+	// not multiple have at most one value.
+	CustomFields map[int][]string
+}
+
+// Attr returns the value of the named attribute.
+func (s *IssueState) Attr(name string) string {
+	return s.Attributes[name]
+}
+
+// AttrId returns the value of the named attribute as an id, or 0 if unset.
+func (s *IssueState) AttrId(name string) int {
+	id, _ := strconv.Atoi(s.Attributes[name])
+	return id
+}
+
+// IssueNames resolves the ids found in journal details to names.
+type IssueNames struct {
+	Projects     map[int]string
+	Trackers     map[int]string
+	Statuses     map[int]string
+	Priorities   map[int]string
+	Users        map[int]string
+	Categories   map[int]string
+	Versions     map[int]string
+	CustomFields map[int]string
+}
+
+// ChangelogEntry is a single change of an issue field.
+type ChangelogEntry struct {
+	Time      time.Time
+	JournalId int
+	User      string
+	Property  string // "attr", "cf", "attachment" or "relation"
+	Field     string // attribute name, or custom field name for "cf"
+	OldValue  string
+	NewValue  string
+}
+
+func parseRedmineTime(s string) (time.Time, error) {
+	return time.Parse(time.RFC3339, s)
+}
+
+func idString(v *IdName) string {
+	if v == nil || v.Id == 0 {
+		return ""
+	}
+	return strconv.Itoa(v.Id)
+}
+
+func intString(i int) string {
+	if i == 0 {
+		return ""
+	}
+	return strconv.Itoa(i)
+}
+
+func floatString(f float32) string {
+	if f == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(float64(f), 'f', -1, 32)
+}
+
+func customFieldValues(v interface{}) []string {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if value == "" {
+			return nil
+		}
+		return []string{value}
+	case []interface{}:
+		var values []string
+		for _, e := range value {
+			if s, ok := e.(string); ok && s != "" {
+				values = append(values, s)
+			} else if !ok && e != nil {
+				values = append(values, fmt.Sprint(e))
+			}
+		}
+		return values
+	case []string:
+		return append([]string(nil), value...)
+	}
+	return []string{fmt.Sprint(v)}
+}
+
+// currentIssueState returns the state of the issue as it was fetched.
+func currentIssueState(issue *Issue) *IssueState {
+	projectId := intString(issue.ProjectId)
+	if issue.Project != nil {
+		projectId = idString(issue.Project)
+	}
+	parentId := intString(issue.ParentId)
+	if issue.Parent != nil {
+		parentId = intString(issue.Parent.Id)
+	}
+	s := &IssueState{
+		Attributes: map[string]string{
+			"project_id":       projectId,
+			"tracker_id":       idString(issue.Tracker),
+			"subject":          issue.Subject,
+			"description":      issue.Description,
+			"status_id":        idString(issue.Status),
+			"priority_id":      idString(issue.Priority),
+			"assigned_to_id":   idString(issue.AssignedTo),
+			"category_id":      idString(issue.Category),
+			"fixed_version_id": idString(issue.FixedVersion),
+			"parent_id":        parentId,
+			"start_date":       issue.StartDate,
+			"due_date":         issue.DueDate,
+			"done_ratio":       strconv.Itoa(int(issue.DoneRatio)),
+			"estimated_hours":  floatString(issue.EstimatedHours),
+		},
+		CustomFields: map[int][]string{},
+	}
+	for _, cf := range issue.CustomFields {
+		s.CustomFields[cf.Id] = customFieldValues(cf.Value)
+	}
+	return s
+}
+
+func removeValue(values []string, v string) []string {
+	for i, e := range values {
+		if e == v {
+			return append(values[:i:i], values[i+1:]...)
+		}
+	}
+	return values
+}
+
+// revert undoes the journal detail d on the state.
+func (s *IssueState) revert(d JournalDetails) {
+	switch d.Property {
+	case "attr":
+		s.Attributes[d.Name] = d.OldValue
+	case "cf":
+		id, err := strconv.Atoi(d.Name)
+		if err != nil {
+			return
+		}
+		values := s.CustomFields[id]
+		switch {
+		case d.OldValue == "":
+			values = removeValue(values, d.NewValue)
+		case d.NewValue == "":
+			values = append(values, d.OldValue)
+		default:
+			values = append(removeValue(values, d.NewValue), d.OldValue)
+		}
+		s.CustomFields[id] = values
+	}
+}
+
+// sortedJournals returns the journals of the issue in chronological order.
+func sortedJournals(issue *Issue) ([]*Journal, []time.Time, error) {
+	journals := append([]*Journal(nil), issue.Journals...)
+	times := make(map[*Journal]time.Time, len(journals))
+	for _, j := range journals {
+		t, err := parseRedmineTime(j.CreatedOn)
+		if err != nil {
+			return nil, nil, err
+		}
+		times[j] = t
+	}
+	sort.SliceStable(journals, func(a, b int) bool {
+		return times[journals[a]].Before(times[journals[b]])
+	})
+	ts := make([]time.Time, len(journals))
+	for i, j := range journals {
+		ts[i] = times[j]
+	}
+	return journals, ts, nil
+}
+
+// IssueStateAt reconstructs the state of the issue at time t by reverting
+// the changes recorded in its journals after t.
+// The issue has to be fetched with journals included, for example with
+// IssueWithArgs(id, map[string]string{"include": "journals"}).
+func IssueStateAt(issue *Issue, t time.Time) (*IssueState, error) {
+	if created, err := parseRedmineTime(issue.CreatedOn); err == nil && t.Before(created) {
+		return nil, errors.New("Issue did not exist at " + t.Format(time.RFC3339))
+	}
+	journals, times, err := sortedJournals(issue)
+	if err != nil {
+		return nil, err
+	}
+	s := currentIssueState(issue)
+	for i := len(journals) - 1; i >= 0 && times[i].After(t); i-- {
+		details := journals[i].Details
+		for k := len(details) - 1; k >= 0; k-- {
+			s.revert(details[k])
+		}
+	}
+	return s, nil
+}
+
+// IssueStates returns the state of the issue at its creation followed by
+// the state after each journal, in chronological order.
+func IssueStates(issue *Issue) ([]*IssueState, error) {
+	journals, _, err := sortedJournals(issue)
+	if err != nil {
+		return nil, err
+	}
+	states := make([]*IssueState, len(journals)+1)
+	s := currentIssueState(issue)
+	states[len(journals)] = s.clone()
+	for i := len(journals) - 1; i >= 0; i-- {
+		details := journals[i].Details
+		for k := len(details) - 1; k >= 0; k-- {
+			s.revert(details[k])
+		}
+		states[i] = s.clone()
+	}
+	return states, nil
+}
+
+func (s *IssueState) clone() *IssueState {
+	c := &IssueState{
+		Attributes:   make(map[string]string, len(s.Attributes)),
+		CustomFields: make(map[int][]string, len(s.CustomFields)),
+	}
+	for k, v := range s.Attributes {
+		c.Attributes[k] = v
+	}
+	for k, v := range s.CustomFields {
+		c.CustomFields[k] = append([]string(nil), v...)
+	}
+	return c
+}
+
+// IssueChangelog returns the changes recorded in the issue's journals in
+// chronological order. Ids are replaced by names found in names, which may
+// be nil, or in the issue itself.
+func IssueChangelog(issue *Issue, names *IssueNames) ([]ChangelogEntry, error) {
+	journals, times, err := sortedJournals(issue)
+	if err != nil {
+		return nil, err
+	}
+	names = names.withIssue(issue)
+	var entries []ChangelogEntry
+	for i, j := range journals {
+		user := ""
+		if j.User != nil {
+			user = j.User.Name
+		}
+		for _, d := range j.Details {
+			e := ChangelogEntry{
+				Time:      times[i],
+				JournalId: j.Id,
+				User:      user,
+				Property:  d.Property,
+				Field:     d.Name,
+				OldValue:  d.OldValue,
+				NewValue:  d.NewValue,
+			}
+			switch d.Property {
+			case "attr":
+				e.OldValue = names.resolve(d.Name, d.OldValue)
+				e.NewValue = names.resolve(d.Name, d.NewValue)
+			case "cf":
+				if id, err := strconv.Atoi(d.Name); err == nil && names.CustomFields[id] != "" {
+					e.Field = names.CustomFields[id]
+				}
+			}
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// withIssue returns a copy of names completed with the names the issue
+// itself carries.
+func (n *IssueNames) withIssue(issue *Issue) *IssueNames {
+	c := &IssueNames{}
+	if n != nil {
+		*c = *n
+	}
+	add := func(m *map[int]string, v *IdName) {
+		if v == nil || v.Id == 0 {
+			return
+		}
+		dst := make(map[int]string, len(*m)+1)
+		for k, name := range *m {
+			dst[k] = name
+		}
+		if _, ok := dst[v.Id]; !ok {
+			dst[v.Id] = v.Name
+		}
+		*m = dst
+	}
+	add(&c.Projects, issue.Project)
+	add(&c.Trackers, issue.Tracker)
+	add(&c.Statuses, issue.Status)
+	add(&c.Priorities, issue.Priority)
+	add(&c.Users, issue.Author)
+	add(&c.Users, issue.AssignedTo)
+	add(&c.Categories, issue.Category)
+	add(&c.Versions, issue.FixedVersion)
+	for _, j := range issue.Journals {
+		add(&c.Users, j.User)
+	}
+	for _, cf := range issue.CustomFields {
+		add(&c.CustomFields, &IdName{Id: cf.Id, Name: cf.Name})
+	}
+	return c
+}
+
+// names returns the names for the ids of the given attribute, or nil.
+func (n *IssueNames) names(attr string) map[int]string {
+	switch attr {
+	case "project_id":
+		return n.Projects
+	case "tracker_id":
+		return n.Trackers
+	case "status_id":
+		return n.Statuses
+	case "priority_id":
+		return n.Priorities
+	case "assigned_to_id":
+		return n.Users
+	case "category_id":
+		return n.Categories
+	case "fixed_version_id":
+		return n.Versions
+	}
+	return nil
+}
+
+// Id returns the id of the given attribute ("status_id", "tracker_id", ...)
+// whose name is name, compared case-insensitively. Numeric names matching
+// no name are taken as ids if known.
+func (n *IssueNames) Id(attr string, name string) (int, bool) {
+	return lookupName(n.names(attr), name)
+}
+
+func (n *IssueNames) resolve(attr string, value string) string {
+	m := n.names(attr)
+	if m == nil {
+		return value
+	}
+	id, err := strconv.Atoi(value)
+	if err != nil {
+		return value
+	}
+	if name, ok := m[id]; ok {
+		return name
+	}
+	return value
+}
+
+// IssueNames fetches the names needed to resolve the ids found in the
+// journals of issues of the given project.
+func (c *Client) IssueNames(projectId int) (*IssueNames, error) {
+	n := &IssueNames{
+		Projects:   map[int]string{},
+		Trackers:   map[int]string{},
+		Statuses:   map[int]string{},
+		Priorities: map[int]string{},
+		Users:      map[int]string{},
+		Categories: map[int]string{},
+		Versions:   map[int]string{},
+	}
+	projects, err := c.allProjects()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range projects {
+		n.Projects[p.Id] = p.Name
+	}
+	trackers, err := c.Trackers()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range trackers {
+		n.Trackers[t.Id] = t.Name
+	}
+	statuses, err := c.IssueStatuses()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range statuses {
+		n.Statuses[s.Id] = s.Name
+	}
+	priorities, err := c.IssuePriorities()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range priorities {
+		n.Priorities[p.Id] = p.Name
+	}
+	memberships, err := c.allMemberships(projectId)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range memberships {
+		if m.Group != nil {
+			n.Users[m.Group.Id] = m.Group.Name
+		} else if m.User.Id != 0 {
+			n.Users[m.User.Id] = m.User.Name
+		}
+	}
+	categories, err := c.IssueCategories(projectId)
+	if err != nil {
+		return nil, err
+	}
+	for _, cat := range categories {
+		n.Categories[cat.Id] = cat.Name
+	}
+	versions, err := c.Versions(projectId)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		n.Versions[v.Id] = v.Name
+	}
+	return n, nil
+}
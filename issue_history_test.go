@@ -0,0 +1,142 @@
+package redmine
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// historyIssue is an issue created on January 1st with status 1, custom
+// field 5 (multiple) set to "b" and custom field 6 set to "w". Its journals
+// are listed out of order.
+const historyIssue = `{
+	"id": 1,
+	"subject": "Now",
+	"status": {"id": 3, "name": "Closed"},
+	"created_on": "2024-01-01T00:00:00Z",
+	"custom_fields": [
+		{"id": 5, "name": "Platforms", "multiple": true, "value": ["a", "c"]},
+		{"id": 6, "name": "Severity", "value": "x"}
+	],
+	"journals": [
+		{"id": 12, "user": {"id": 7, "name": "Bob"}, "created_on": "2024-01-03T00:00:00Z", "details": [
+			{"property": "cf", "name": "5", "old_value": "b", "new_value": null},
+			{"property": "cf", "name": "5", "old_value": null, "new_value": "c"},
+			{"property": "cf", "name": "6", "old_value": "w", "new_value": "x"},
+			{"property": "attr", "name": "status_id", "old_value": "2", "new_value": "3"},
+			{"property": "attr", "name": "subject", "old_value": "Then", "new_value": "Now"}
+		]},
+		{"id": 11, "user": {"id": 7, "name": "Bob"}, "created_on": "2024-01-02T00:00:00Z", "details": [
+			{"property": "cf", "name": "5", "old_value": null, "new_value": "a"},
+			{"property": "attr", "name": "status_id", "old_value": "1", "new_value": "2"}
+		]}
+	]
+}`
+
+func loadHistoryIssue(t *testing.T) *Issue {
+	t.Helper()
+	var issue Issue
+	if err := json.Unmarshal([]byte(historyIssue), &issue); err != nil {
+		t.Fatal(err)
+	}
+	return &issue
+}
+
+func sortedValues(values []string) []string {
+	values = append([]string{}, values...)
+	sort.Strings(values)
+	return values
+}
+
+func TestIssueStateAt(t *testing.T) {
+	tests := []struct {
+		at       string
+		status   string
+		subject  string
+		platform []string
+		severity []string
+	}{
+		{"2024-01-01T12:00:00Z", "1", "Then", []string{"b"}, []string{"w"}},
+		{"2024-01-02T00:00:00Z", "2", "Then", []string{"a", "b"}, []string{"w"}},
+		{"2024-01-02T12:00:00Z", "2", "Then", []string{"a", "b"}, []string{"w"}},
+		{"2024-01-03T12:00:00Z", "3", "Now", []string{"a", "c"}, []string{"x"}},
+	}
+	issue := loadHistoryIssue(t)
+	for _, tt := range tests {
+		t.Run(tt.at, func(t *testing.T) {
+			at, _ := time.Parse(time.RFC3339, tt.at)
+			s, err := IssueStateAt(issue, at)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Attr("status_id") != tt.status {
+				t.Errorf("status_id = %q, want %q", s.Attr("status_id"), tt.status)
+			}
+			if s.Attr("subject") != tt.subject {
+				t.Errorf("subject = %q, want %q", s.Attr("subject"), tt.subject)
+			}
+			if got := sortedValues(s.CustomFields[5]); !reflect.DeepEqual(got, tt.platform) {
+				t.Errorf("custom field 5 = %v, want %v", got, tt.platform)
+			}
+			if got := s.CustomFields[6]; !reflect.DeepEqual(got, tt.severity) {
+				t.Errorf("custom field 6 = %v, want %v", got, tt.severity)
+			}
+		})
+	}
+}
+
+func TestIssueStateAtBeforeCreation(t *testing.T) {
+	at, _ := time.Parse(time.RFC3339, "2023-12-31T00:00:00Z")
+	if _, err := IssueStateAt(loadHistoryIssue(t), at); err == nil {
+		t.Error("expected an error before the creation of the issue")
+	}
+}
+
+func TestIssueStates(t *testing.T) {
+	issue := loadHistoryIssue(t)
+	states, err := IssueStates(issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var statuses []string
+	for _, s := range states {
+		statuses = append(statuses, s.Attr("status_id"))
+	}
+	if want := []string{"1", "2", "3"}; !reflect.DeepEqual(statuses, want) {
+		t.Errorf("statuses = %v, want %v", statuses, want)
+	}
+	// States are independent copies.
+	states[0].CustomFields[5][0] = "changed"
+	if states[1].CustomFields[5][0] == "changed" {
+		t.Error("states share custom field values")
+	}
+}
+
+func TestIssueChangelog(t *testing.T) {
+	issue := loadHistoryIssue(t)
+	names := &IssueNames{Statuses: map[int]string{1: "New", 2: "In Progress"}}
+	entries, err := IssueChangelog(issue, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 7 {
+		t.Fatalf("got %d entries, want 7", len(entries))
+	}
+	tests := []struct {
+		i        int
+		field    string
+		old, new string
+	}{
+		{0, "Platforms", "", "a"},
+		{1, "status_id", "New", "In Progress"},
+		{5, "status_id", "In Progress", "Closed"},
+	}
+	for _, tt := range tests {
+		e := entries[tt.i]
+		if e.Field != tt.field || e.OldValue != tt.old || e.NewValue != tt.new || e.User != "Bob" {
+			t.Errorf("entry %d = %+v, want %s %q -> %q", tt.i, e, tt.field, tt.old, tt.new)
+		}
+	}
+}
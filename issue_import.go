@@ -0,0 +1,445 @@
+package redmine
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportRow is a row of source data, keyed by column name.
+type ImportRow map[string]string
+
+// ReadImportCSV reads rows from CSV data whose first line names the columns.
+func ReadImportCSV(r io.Reader) ([]ImportRow, error) {
+	cr := csv.NewReader(r)
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	rows := make([]ImportRow, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := ImportRow{}
+		for i, v := range record {
+			if i < len(header) {
+				row[header[i]] = v
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// ReadImportJSONL reads rows from JSON Lines data, one object per line.
+// Non-string values are converted with fmt.Sprint.
+func ReadImportJSONL(r io.Reader) ([]ImportRow, error) {
+	var rows []ImportRow
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var obj map[string]interface{}
+		decoder := json.NewDecoder(strings.NewReader(line))
+		// Keeps large ids such as 1234567 from being formatted as floats.
+		decoder.UseNumber()
+		if err := decoder.Decode(&obj); err != nil {
+			return nil, err
+		}
+		row := ImportRow{}
+		for k, v := range obj {
+			if v != nil {
+				row[k] = fmt.Sprint(v)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+// ImportReport maps row keys to the ids of the issues created for them and
+// records the relations created. Passing the report of a previous run to
+// the Importer skips what has already been imported.
+type ImportReport struct {
+	Issues    map[string]int  `json:"issues"`
+	Relations map[string]bool `json:"relations"`
+}
+
+// ImportError is a problem found in a row.
+type ImportError struct {
+	Row    int // 1-based, not counting the CSV header
+	Key    string
+	Column string
+	Err    string
+}
+
+func (e ImportError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("row %d (%s): %s", e.Row, e.Key, e.Err)
+	}
+	return fmt.Sprintf("row %d (%s): %s: %s", e.Row, e.Key, e.Column, e.Err)
+}
+
+// ImportErrors are all the problems found while validating rows.
+type ImportErrors []ImportError
+
+func (e ImportErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, err := range e {
+		lines[i] = err.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Importer creates issues from rows.
+//
+// Mapping maps source columns to issue fields: "key", "subject",
+// "description", "tracker", "status", "priority", "assigned_to",
+// "category", "fixed_version", "parent", "start_date", "due_date",
+// "estimated_hours", "done_ratio", "cf:<custom field name>" and
+// "relation:<type>" (for example "relation:blocks").
+//
+// The "key" column identifies rows in the report; rows are identified by
+// their number when it is not mapped. "parent" and "relation:<type>"
+// columns refer to other rows by key, or to existing issues as "#<id>".
+// Relations may list several references separated by commas or spaces.
+//
+// Names are resolved to ids with Names, case-insensitively. Numeric values
+// matching no name are taken as ids, which have to be in Names too when
+// the names of the field are set. Custom fields are only resolved if
+// Names.CustomFields is set, for example from Client.CustomFields.
+type Importer struct {
+	Client    *Client
+	ProjectId int
+	Mapping   map[string]string
+	Names     *IssueNames
+
+	// DryRun validates the rows and returns a copy of the report with
+	// negative ids for the issues that would be created, without creating
+	// anything.
+	DryRun bool
+
+	// Report is updated as issues and relations are created, unless in
+	// DryRun. It may hold the report of a previous run.
+	Report *ImportReport
+}
+
+type importItem struct {
+	row       int
+	key       string
+	issue     Issue
+	parent    string
+	relations []importRelation
+}
+
+type importRelation struct {
+	relationType string
+	to           string
+}
+
+var importRelationTypes = []string{"relates", "duplicates", "duplicated", "blocks", "blocked", "precedes", "follows", "copied_to", "copied_from"}
+
+// lookupName returns the id whose name is value, compared
+// case-insensitively. Numeric values matching no name, such as version "2",
+// are taken as ids if m has them, or if m is nil.
+func lookupName(m map[int]string, value string) (int, bool) {
+	for id, name := range m {
+		if strings.EqualFold(name, value) {
+			return id, true
+		}
+	}
+	if id, err := strconv.Atoi(value); err == nil {
+		if _, ok := m[id]; ok || m == nil {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+func splitReferences(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == ';'
+	})
+}
+
+// Validate converts rows to issues without sending anything. It returns
+// ImportErrors listing every problem found.
+func (im *Importer) Validate(rows []ImportRow) error {
+	_, err := im.prepare(rows)
+	return err
+}
+
+func (im *Importer) prepare(rows []ImportRow) ([]*importItem, error) {
+	names := im.Names
+	if names == nil {
+		names = &IssueNames{}
+	}
+	var errs ImportErrors
+	items := make([]*importItem, 0, len(rows))
+	keys := map[string]bool{}
+
+	for n, row := range rows {
+		item := &importItem{row: n + 1, key: strconv.Itoa(n + 1)}
+		item.issue.ProjectId = im.ProjectId
+		for column, field := range im.Mapping {
+			if field == "key" && row[column] != "" {
+				item.key = row[column]
+			}
+		}
+		fail := func(column, format string, args ...interface{}) {
+			errs = append(errs, ImportError{Row: item.row, Key: item.key, Column: column, Err: fmt.Sprintf(format, args...)})
+		}
+		if keys[item.key] {
+			fail("", "duplicate key")
+		}
+		keys[item.key] = true
+
+		columns := make([]string, 0, len(im.Mapping))
+		for column := range im.Mapping {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		for _, column := range columns {
+			field := im.Mapping[column]
+			value := strings.TrimSpace(row[column])
+			if value == "" || field == "key" {
+				continue
+			}
+			resolve := func(m map[int]string, what string) int {
+				id, ok := lookupName(m, value)
+				if !ok {
+					fail(column, "unknown %s %q", what, value)
+				}
+				return id
+			}
+			switch {
+			case field == "subject":
+				item.issue.Subject = value
+			case field == "description":
+				item.issue.Description = value
+			case field == "tracker":
+				item.issue.TrackerId = resolve(names.Trackers, "tracker")
+			case field == "status":
+				item.issue.StatusId = resolve(names.Statuses, "status")
+			case field == "priority":
+				item.issue.PriorityId = resolve(names.Priorities, "priority")
+			case field == "assigned_to":
+				item.issue.AssignedToId = resolve(names.Users, "user")
+			case field == "category":
+				item.issue.CategoryId = resolve(names.Categories, "category")
+			case field == "fixed_version":
+				item.issue.FixedVersionId = resolve(names.Versions, "version")
+			case field == "start_date", field == "due_date":
+				if _, err := time.Parse("2006-01-02", value); err != nil {
+					fail(column, "invalid date %q", value)
+				}
+				if field == "start_date" {
+					item.issue.StartDate = value
+				} else {
+					item.issue.DueDate = value
+				}
+			case field == "estimated_hours":
+				f, err := strconv.ParseFloat(value, 32)
+				if err != nil || f < 0 {
+					fail(column, "invalid hours %q", value)
+				}
+				item.issue.EstimatedHours = float32(f)
+			case field == "done_ratio":
+				i, err := strconv.Atoi(value)
+				if err != nil || i < 0 || i > 100 {
+					fail(column, "invalid done ratio %q", value)
+				}
+				item.issue.DoneRatio = float32(i)
+			case field == "parent":
+				item.parent = value
+			case strings.HasPrefix(field, "cf:"):
+				id, ok := lookupName(names.CustomFields, field[3:])
+				if !ok {
+					fail(column, "unknown custom field %q", field[3:])
+					continue
+				}
+				item.issue.CustomFields = append(item.issue.CustomFields, &CustomField{Id: id, Value: value})
+			case strings.HasPrefix(field, "relation:"):
+				relationType := field[len("relation:"):]
+				valid := false
+				for _, t := range importRelationTypes {
+					valid = valid || t == relationType
+				}
+				if !valid {
+					fail(column, "unknown relation type %q", relationType)
+					continue
+				}
+				for _, ref := range splitReferences(value) {
+					item.relations = append(item.relations, importRelation{relationType, ref})
+				}
+			default:
+				fail(column, "unknown field %q", field)
+			}
+		}
+		if item.issue.Subject == "" {
+			fail("", "subject is required")
+		}
+		items = append(items, item)
+	}
+
+	// References to other rows must exist.
+	for _, item := range items {
+		refs := []string{}
+		if item.parent != "" {
+			refs = append(refs, item.parent)
+		}
+		for _, r := range item.relations {
+			refs = append(refs, r.to)
+		}
+		for _, ref := range refs {
+			if strings.HasPrefix(ref, "#") {
+				if _, err := strconv.Atoi(ref[1:]); err != nil {
+					errs = append(errs, ImportError{Row: item.row, Key: item.key, Err: "invalid issue reference " + ref})
+				}
+			} else if !keys[ref] {
+				errs = append(errs, ImportError{Row: item.row, Key: item.key, Err: "unknown row reference " + ref})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	ordered, err := orderImportItems(items)
+	if err != nil {
+		return nil, err
+	}
+	return ordered, nil
+}
+
+// orderImportItems sorts items so that parents come before their children.
+func orderImportItems(items []*importItem) ([]*importItem, error) {
+	byKey := make(map[string]*importItem, len(items))
+	for _, item := range items {
+		byKey[item.key] = item
+	}
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := map[*importItem]int{}
+	ordered := make([]*importItem, 0, len(items))
+	var visit func(item *importItem) error
+	visit = func(item *importItem) error {
+		switch state[item] {
+		case visiting:
+			return ImportErrors{{Row: item.row, Key: item.key, Column: "parent", Err: "parent cycle"}}
+		case done:
+			return nil
+		}
+		state[item] = visiting
+		if parent, ok := byKey[item.parent]; ok {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		state[item] = done
+		ordered = append(ordered, item)
+		return nil
+	}
+	for _, item := range items {
+		if err := visit(item); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Import validates all rows, then creates the issues that are not in the
+// report yet, parents first, and finally the relations between them.
+// Nothing is sent if any row is invalid.
+func (im *Importer) Import(rows []ImportRow) (*ImportReport, error) {
+	items, err := im.prepare(rows)
+	if err != nil {
+		return nil, err
+	}
+	if im.Report == nil {
+		im.Report = &ImportReport{}
+	}
+	report := im.Report
+	if im.DryRun {
+		// Keep the real report free of the made up ids.
+		report = &ImportReport{}
+	}
+	if report.Issues == nil {
+		report.Issues = map[string]int{}
+	}
+	if report.Relations == nil {
+		report.Relations = map[string]bool{}
+	}
+	if im.DryRun {
+		for k, v := range im.Report.Issues {
+			report.Issues[k] = v
+		}
+		for k, v := range im.Report.Relations {
+			report.Relations[k] = v
+		}
+	}
+
+	resolve := func(ref string) int {
+		if strings.HasPrefix(ref, "#") {
+			id, _ := strconv.Atoi(ref[1:])
+			return id
+		}
+		return report.Issues[ref]
+	}
+
+	fake := 0
+	for _, item := range items {
+		if _, ok := report.Issues[item.key]; ok {
+			continue
+		}
+		if im.DryRun {
+			fake--
+			report.Issues[item.key] = fake
+			continue
+		}
+		issue := item.issue
+		if item.parent != "" {
+			issue.ParentId = resolve(item.parent)
+			issue.Parent = &Id{issue.ParentId}
+		}
+		created, err := im.Client.CreateIssue(issue)
+		if err != nil {
+			return report, ImportError{Row: item.row, Key: item.key, Err: err.Error()}
+		}
+		report.Issues[item.key] = created.Id
+	}
+
+	for _, item := range items {
+		for _, r := range item.relations {
+			name := item.key + " " + r.relationType + " " + r.to
+			if report.Relations[name] {
+				continue
+			}
+			if !im.DryRun {
+				_, err := im.Client.CreateIssueRelation(IssueRelation{
+					IssueId:      report.Issues[item.key],
+					IssueToId:    resolve(r.to),
+					RelationType: r.relationType,
+				})
+				if err != nil {
+					return report, ImportError{Row: item.row, Key: item.key, Column: "relation:" + r.relationType, Err: err.Error()}
+				}
+			}
+			report.Relations[name] = true
+		}
+	}
+	return report, nil
+}
@@ -0,0 +1,215 @@
+package redmine
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadImport(t *testing.T) {
+	csvRows, err := ReadImportCSV(strings.NewReader("Title,Hours\nFirst,2\n\"Second, too\",\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonRows, err := ReadImportJSONL(strings.NewReader("{\"Title\": \"First\", \"Hours\": 2}\n\n{\"Title\": \"Second, too\", \"Hours\": null}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []ImportRow{{"Title": "First", "Hours": "2"}, {"Title": "Second, too", "Hours": ""}}; !reflect.DeepEqual(csvRows, want) {
+		t.Errorf("ReadImportCSV() = %v, want %v", csvRows, want)
+	}
+	if want := []ImportRow{{"Title": "First", "Hours": "2"}, {"Title": "Second, too"}}; !reflect.DeepEqual(jsonRows, want) {
+		t.Errorf("ReadImportJSONL() = %v, want %v", jsonRows, want)
+	}
+}
+
+func TestReadImportJSONLNumbers(t *testing.T) {
+	rows, err := ReadImportJSONL(strings.NewReader("{\"Parent\": 1234567, \"Assignee\": 10000000, \"Hours\": 1.5}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []ImportRow{{"Parent": "1234567", "Assignee": "10000000", "Hours": "1.5"}}; !reflect.DeepEqual(rows, want) {
+		t.Errorf("ReadImportJSONL() = %v, want %v", rows, want)
+	}
+}
+
+func importKeys(items []*importItem) []string {
+	keys := make([]string, len(items))
+	for i, item := range items {
+		keys[i] = item.key
+	}
+	return keys
+}
+
+func TestOrderImportItems(t *testing.T) {
+	tests := []struct {
+		name    string
+		parents [][2]string // key, parent
+		want    []string
+		cycle   bool
+	}{
+		{
+			name:    "parents first",
+			parents: [][2]string{{"c", "b"}, {"b", "a"}, {"a", ""}, {"d", "#12"}},
+			want:    []string{"a", "b", "c", "d"},
+		},
+		{
+			name:    "kept order",
+			parents: [][2]string{{"b", ""}, {"a", ""}, {"c", "a"}},
+			want:    []string{"b", "a", "c"},
+		},
+		{
+			name:    "self parent",
+			parents: [][2]string{{"a", "a"}},
+			cycle:   true,
+		},
+		{
+			name:    "parent cycle",
+			parents: [][2]string{{"x", ""}, {"a", "c"}, {"b", "a"}, {"c", "b"}},
+			cycle:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var items []*importItem
+			for i, p := range tt.parents {
+				items = append(items, &importItem{row: i + 1, key: p[0], parent: p[1]})
+			}
+			ordered, err := orderImportItems(items)
+			if tt.cycle {
+				errs, ok := err.(ImportErrors)
+				if !ok || len(errs) != 1 || errs[0].Column != "parent" {
+					t.Errorf("orderImportItems() error = %v, want a parent cycle", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := importKeys(ordered); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderImportItems() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func testImporter() *Importer {
+	return &Importer{
+		ProjectId: 1,
+		Mapping: map[string]string{
+			"Id":       "key",
+			"Title":    "subject",
+			"Type":     "tracker",
+			"Version":  "fixed_version",
+			"Parent":   "parent",
+			"Blocks":   "relation:blocks",
+			"Due":      "due_date",
+			"Hours":    "estimated_hours",
+			"Done":     "done_ratio",
+			"Severity": "cf:Severity",
+		},
+		Names: &IssueNames{
+			Trackers:     map[int]string{1: "Bug", 2: "Feature"},
+			Versions:     map[int]string{3: "1.0", 4: "2"},
+			CustomFields: map[int]string{9: "Severity"},
+		},
+		DryRun: true,
+	}
+}
+
+func TestImporterValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []ImportRow
+		errs []string
+	}{
+		{
+			name: "valid",
+			rows: []ImportRow{
+				{"Id": "A", "Title": "First", "Type": "bug", "Version": "1.0", "Due": "2024-02-01", "Hours": "1.5", "Done": "50", "Severity": "High"},
+				{"Id": "B", "Title": "Second", "Type": "2", "Parent": "A", "Blocks": "A, #7"},
+			},
+		},
+		{
+			name: "missing subject and duplicate key",
+			rows: []ImportRow{{"Id": "A", "Title": "First"}, {"Id": "A"}},
+			errs: []string{"row 2 (A): duplicate key", "row 2 (A): subject is required"},
+		},
+		{
+			name: "unknown names and invalid values",
+			rows: []ImportRow{{"Title": "First", "Type": "Task", "Due": "tomorrow", "Hours": "-1", "Done": "101"}},
+			errs: []string{
+				"row 1 (1): Done: invalid done ratio \"101\"",
+				"row 1 (1): Due: invalid date \"tomorrow\"",
+				"row 1 (1): Hours: invalid hours \"-1\"",
+				"row 1 (1): Type: unknown tracker \"Task\"",
+			},
+		},
+		{
+			name: "unknown ids",
+			rows: []ImportRow{{"Title": "First", "Type": "7", "Version": "5"}},
+			errs: []string{
+				"row 1 (1): Type: unknown tracker \"7\"",
+				"row 1 (1): Version: unknown version \"5\"",
+			},
+		},
+		{
+			name: "bad references",
+			rows: []ImportRow{{"Id": "A", "Title": "First", "Parent": "Z", "Blocks": "#x"}},
+			errs: []string{"row 1 (A): unknown row reference Z", "row 1 (A): invalid issue reference #x"},
+		},
+		{
+			name: "parent cycle",
+			rows: []ImportRow{{"Id": "A", "Title": "First", "Parent": "B"}, {"Id": "B", "Title": "Second", "Parent": "A"}},
+			errs: []string{"row 1 (A): parent: parent cycle"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testImporter().Validate(tt.rows)
+			var got []string
+			if errs, ok := err.(ImportErrors); ok {
+				for _, e := range errs {
+					got = append(got, e.Error())
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.errs) {
+				t.Errorf("Validate() = %q, want %q", got, tt.errs)
+			}
+		})
+	}
+}
+
+func TestImporterDryRun(t *testing.T) {
+	im := testImporter()
+	im.Report = &ImportReport{Issues: map[string]int{"A": 100}}
+	rows := []ImportRow{
+		{"Id": "C", "Title": "Third", "Parent": "B", "Version": "2"},
+		{"Id": "B", "Title": "Second", "Parent": "A", "Blocks": "C"},
+		{"Id": "A", "Title": "First"},
+	}
+	report, err := im.Import(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]int{"A": 100, "B": -1, "C": -2}; !reflect.DeepEqual(report.Issues, want) {
+		t.Errorf("Issues = %v, want %v", report.Issues, want)
+	}
+	if !report.Relations["B blocks C"] {
+		t.Errorf("Relations = %v, want B blocks C", report.Relations)
+	}
+	if len(im.Report.Issues) != 1 || im.Report.Relations != nil {
+		t.Errorf("dry run changed the report: %+v", im.Report)
+	}
+
+	items, err := im.prepare(rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Version "2" is a name, not the id 2.
+	if items[2].key != "C" || items[2].issue.FixedVersionId != 4 {
+		t.Errorf("item %s has version %d, want C with version 4", items[2].key, items[2].issue.FixedVersionId)
+	}
+}
@@ -0,0 +1,265 @@
+package redmine
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// MetricsOptions tells ComputeIssueMetrics how to interpret statuses.
+type MetricsOptions struct {
+	// ClosedStatuses are the ids of statuses that close an issue,
+	// see IssueStatus.IsClosed.
+	ClosedStatuses []int
+
+	// InProgressStatuses are the ids of statuses starting the cycle time.
+	// If empty, the cycle starts with the first status change.
+	InProgressStatuses []int
+
+	// Now ends the time spent in the current status and assignee of open
+	// issues. The zero value means time.Now().
+	Now time.Time
+}
+
+// IssueMetrics holds the time an issue spent in each status and with each
+// assignee, and its lead and cycle times.
+type IssueMetrics struct {
+	IssueId int
+	Project *IdName
+	Tracker *IdName
+
+	// StatusDurations maps status ids to the time spent in that status.
+	StatusDurations map[int]time.Duration
+	// AssigneeDurations maps user ids to the time the issue was assigned to
+	// them. Id 0 counts the time the issue was unassigned.
+	AssigneeDurations map[int]time.Duration
+
+	CreatedOn time.Time
+	StartedOn time.Time // zero if the issue never went in progress
+	ClosedOn  time.Time // zero if the issue is open
+
+	LeadTime  time.Duration // from creation to close, 0 if open
+	CycleTime time.Duration // from first in progress to close, 0 if open
+}
+
+func containsInt(ids []int, id int) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ComputeIssueMetrics computes the metrics of an issue fetched with its
+// journals. Time spent in closed statuses is not counted.
+// opts may be nil.
+func ComputeIssueMetrics(issue *Issue, opts *MetricsOptions) (*IssueMetrics, error) {
+	if opts == nil {
+		opts = &MetricsOptions{}
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	created, err := parseRedmineTime(issue.CreatedOn)
+	if err != nil {
+		return nil, err
+	}
+	_, times, err := sortedJournals(issue)
+	if err != nil {
+		return nil, err
+	}
+	states, err := IssueStates(issue)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &IssueMetrics{
+		IssueId:           issue.Id,
+		Project:           issue.Project,
+		Tracker:           issue.Tracker,
+		StatusDurations:   map[int]time.Duration{},
+		AssigneeDurations: map[int]time.Duration{},
+		CreatedOn:         created,
+	}
+	initialStatus := states[0].AttrId("status_id")
+	for i, s := range states {
+		from := created
+		if i > 0 {
+			from = times[i-1]
+		}
+		to := now
+		if i < len(times) {
+			to = times[i]
+		}
+		status := s.AttrId("status_id")
+		closed := containsInt(opts.ClosedStatuses, status)
+		if !closed {
+			m.StatusDurations[status] += to.Sub(from)
+			m.AssigneeDurations[s.AttrId("assigned_to_id")] += to.Sub(from)
+			m.ClosedOn = time.Time{}
+		} else if m.ClosedOn.IsZero() {
+			m.ClosedOn = from
+		}
+		if m.StartedOn.IsZero() && !closed {
+			if len(opts.InProgressStatuses) > 0 {
+				if containsInt(opts.InProgressStatuses, status) {
+					m.StartedOn = from
+				}
+			} else if status != initialStatus {
+				m.StartedOn = from
+			}
+		}
+	}
+	if !m.ClosedOn.IsZero() {
+		m.LeadTime = m.ClosedOn.Sub(created)
+		if !m.StartedOn.IsZero() {
+			m.CycleTime = m.ClosedOn.Sub(m.StartedOn)
+		}
+	}
+	return m, nil
+}
+
+// Percentiles summarizes a set of durations.
+type Percentiles struct {
+	Count int
+	P50   time.Duration
+	P85   time.Duration
+	P95   time.Duration
+	Max   time.Duration
+}
+
+// Percentile returns the p-th percentile (0 < p <= 100) of durations using
+// the nearest-rank method. durations must be sorted.
+func Percentile(durations []time.Duration, p float64) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(durations))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(durations) {
+		rank = len(durations)
+	}
+	return durations[rank-1]
+}
+
+func newPercentiles(durations []time.Duration) Percentiles {
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	p := Percentiles{
+		Count: len(durations),
+		P50:   Percentile(durations, 50),
+		P85:   Percentile(durations, 85),
+		P95:   Percentile(durations, 95),
+	}
+	if len(durations) > 0 {
+		p.Max = durations[len(durations)-1]
+	}
+	return p
+}
+
+// MetricsSummary aggregates the metrics of a group of issues.
+// Lead and cycle times only account for closed issues.
+type MetricsSummary struct {
+	Group     string
+	Issues    int
+	LeadTime  Percentiles
+	CycleTime Percentiles
+	// TimeInStatus maps status ids to the time issues spent in them.
+	TimeInStatus map[int]Percentiles
+}
+
+// MetricsByTracker groups metrics by tracker name.
+func MetricsByTracker(m *IssueMetrics) string {
+	if m.Tracker == nil {
+		return ""
+	}
+	return m.Tracker.Name
+}
+
+// MetricsByProject groups metrics by project name.
+func MetricsByProject(m *IssueMetrics) string {
+	if m.Project == nil {
+		return ""
+	}
+	return m.Project.Name
+}
+
+// SummarizeMetrics aggregates metrics into the groups returned by group,
+// ordered by group name.
+func SummarizeMetrics(metrics []*IssueMetrics, group func(*IssueMetrics) string) []MetricsSummary {
+	type durations struct {
+		issues   int
+		lead     []time.Duration
+		cycle    []time.Duration
+		inStatus map[int][]time.Duration
+	}
+	groups := map[string]*durations{}
+	for _, m := range metrics {
+		name := group(m)
+		d, ok := groups[name]
+		if !ok {
+			d = &durations{inStatus: map[int][]time.Duration{}}
+			groups[name] = d
+		}
+		d.issues++
+		if !m.ClosedOn.IsZero() {
+			d.lead = append(d.lead, m.LeadTime)
+			if !m.StartedOn.IsZero() {
+				d.cycle = append(d.cycle, m.CycleTime)
+			}
+		}
+		for status, t := range m.StatusDurations {
+			d.inStatus[status] = append(d.inStatus[status], t)
+		}
+	}
+
+	summaries := make([]MetricsSummary, 0, len(groups))
+	for name, d := range groups {
+		s := MetricsSummary{
+			Group:        name,
+			Issues:       d.issues,
+			LeadTime:     newPercentiles(d.lead),
+			CycleTime:    newPercentiles(d.cycle),
+			TimeInStatus: map[int]Percentiles{},
+		}
+		for status, ds := range d.inStatus {
+			s.TimeInStatus[status] = newPercentiles(ds)
+		}
+		summaries = append(summaries, s)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Group < summaries[j].Group })
+	return summaries
+}
+
+// IssuesWithJournals fetches the issues matching filter, each one with its
+// journals. The issue list API does not return journals, so the journals of
+// every issue are fetched separately and added to the listed issue, which
+// keeps what the filter included.
+func (c *Client) IssuesWithJournals(filter *IssueFilter) ([]Issue, error) {
+	issues, err := c.IssuesByFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	for i := range issues {
+		issue, err := getOneIssue(c, issues[i].Id, map[string]string{"include": "journals"})
+		if err != nil {
+			return nil, err
+		}
+		issues[i].Journals = issue.Journals
+	}
+	return issues, nil
+}
+
+// ClosedStatusIds returns the ids of the statuses that close issues.
+func ClosedStatusIds(statuses []IssueStatus) []int {
+	var ids []int
+	for _, s := range statuses {
+		if s.IsClosed {
+			ids = append(ids, s.Id)
+		}
+	}
+	return ids
+}
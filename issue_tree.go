@@ -0,0 +1,160 @@
+package redmine
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// IssueNode is an issue of a parent/child tree together with the values
+// rolled up over its subtree.
+type IssueNode struct {
+	Issue    *Issue
+	Children []*IssueNode
+
+	// EstimatedHours and SpentHours are the totals of the issue and all of
+	// its descendants.
+	EstimatedHours float64
+	SpentHours     float64
+
+	// DoneRatio is the issue's own done ratio for leaves. For parents it is
+	// the average of the children weighted by their estimated hours, closed
+	// children counting as done, the way Redmine computes it when done
+	// ratios are derived from subtasks.
+	DoneRatio float64
+}
+
+// IssueTree fetches the issue with the given id and all of its descendants
+// and returns them as a tree.
+func (c *Client) IssueTree(rootId int) (*IssueNode, error) {
+	root, err := c.Issue(rootId)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := c.IssueStatuses()
+	if err != nil {
+		return nil, err
+	}
+	descendants, err := c.IssuesByFilter(&IssueFilter{
+		StatusId:     "*",
+		ExtraFilters: map[string]string{"parent_id": "~" + strconv.Itoa(rootId)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	issues := []Issue{*root}
+	for _, issue := range descendants {
+		if issue.Id != rootId {
+			issues = append(issues, issue)
+		}
+	}
+	for _, node := range BuildIssueForest(issues, ClosedStatusIds(statuses)) {
+		if node.Issue.Id == rootId {
+			return node, nil
+		}
+	}
+	return nil, errors.New("Not Found")
+}
+
+// ProjectIssueForest fetches all issues of the given project and returns
+// the trees they form. Issues whose parent belongs to another project are
+// roots of their own tree.
+func (c *Client) ProjectIssueForest(projectId int) ([]*IssueNode, error) {
+	issues, err := c.IssuesByFilter(&IssueFilter{
+		ProjectId: strconv.Itoa(projectId),
+		StatusId:  "*",
+	})
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := c.IssueStatuses()
+	if err != nil {
+		return nil, err
+	}
+	return BuildIssueForest(issues, ClosedStatusIds(statuses)), nil
+}
+
+// BuildIssueForest assembles issues into parent/child trees and computes
+// the rolled-up values of every node. Roots and children are ordered by id.
+// Children with a status in closedStatuses count as 100% done.
+func BuildIssueForest(issues []Issue, closedStatuses []int) []*IssueNode {
+	nodes := make(map[int]*IssueNode, len(issues))
+	for i := range issues {
+		nodes[issues[i].Id] = &IssueNode{Issue: &issues[i]}
+	}
+	var roots []*IssueNode
+	for i := range issues {
+		node := nodes[issues[i].Id]
+		if p := issues[i].Parent; p != nil {
+			if parent, ok := nodes[p.Id]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+	sortIssueNodes(roots)
+	for _, root := range roots {
+		root.rollup(closedStatuses)
+	}
+	return roots
+}
+
+func sortIssueNodes(nodes []*IssueNode) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Issue.Id < nodes[j].Issue.Id })
+}
+
+func (n *IssueNode) rollup(closedStatuses []int) {
+	n.EstimatedHours = float64(n.Issue.EstimatedHours)
+	n.SpentHours = float64(n.Issue.SpentHours)
+	n.DoneRatio = float64(n.Issue.DoneRatio)
+	if len(n.Children) == 0 {
+		return
+	}
+	sortIssueNodes(n.Children)
+
+	var estimated, estimatedCount float64
+	for _, child := range n.Children {
+		child.rollup(closedStatuses)
+		n.EstimatedHours += child.EstimatedHours
+		n.SpentHours += child.SpentHours
+		if child.EstimatedHours > 0 {
+			estimated += child.EstimatedHours
+			estimatedCount++
+		}
+	}
+
+	// Children without estimate weigh as much as the average estimated
+	// child, or all children weigh the same if none is estimated.
+	average := 1.0
+	if estimatedCount > 0 {
+		average = estimated / estimatedCount
+	}
+	var done, weight float64
+	for _, child := range n.Children {
+		w := child.EstimatedHours
+		if w <= 0 {
+			w = average
+		}
+		ratio := child.DoneRatio
+		if child.Issue.Status != nil && containsInt(closedStatuses, child.Issue.Status.Id) {
+			ratio = 100
+		}
+		done += ratio * w
+		weight += w
+	}
+	n.DoneRatio = done / weight
+}
+
+// Walk calls fn for the node and all of its descendants in depth-first
+// order, with the depth of each node relative to n.
+func (n *IssueNode) Walk(fn func(node *IssueNode, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *IssueNode) walk(fn func(node *IssueNode, depth int), depth int) {
+	fn(n, depth)
+	for _, child := range n.Children {
+		child.walk(fn, depth+1)
+	}
+}
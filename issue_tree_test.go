@@ -0,0 +1,38 @@
+package redmine
+
+import "testing"
+
+func TestBuildIssueForest(t *testing.T) {
+	issues := []Issue{
+		{Id: 3, Parent: &Id{1}, Status: &IdName{Id: 5}, DoneRatio: 20, EstimatedHours: 2},
+		{Id: 1},
+		{Id: 2, Parent: &Id{1}, Status: &IdName{Id: 1}, DoneRatio: 50, EstimatedHours: 6, SpentHours: 1},
+		{Id: 4, Parent: &Id{9}},
+	}
+	tests := []struct {
+		name   string
+		closed []int
+		done   float64
+	}{
+		{"own ratios", nil, (50*6 + 20*2) / 8.0},
+		{"closed child done", []int{5}, (50*6 + 100*2) / 8.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roots := BuildIssueForest(append([]Issue(nil), issues...), tt.closed)
+			if len(roots) != 2 || roots[0].Issue.Id != 1 || roots[1].Issue.Id != 4 {
+				t.Fatalf("got %d roots, want issues 1 and 4", len(roots))
+			}
+			root := roots[0]
+			if len(root.Children) != 2 || root.Children[0].Issue.Id != 2 {
+				t.Fatalf("children of 1 are not ordered by id")
+			}
+			if root.EstimatedHours != 8 || root.SpentHours != 1 || root.DoneRatio != tt.done {
+				t.Errorf("root = %v h, %v h, %v%%, want 8 h, 1 h, %v%%", root.EstimatedHours, root.SpentHours, root.DoneRatio, tt.done)
+			}
+			if root.Children[1].DoneRatio != 20 {
+				t.Errorf("leaf done ratio = %v, want its own 20", root.Children[1].DoneRatio)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 
 type membershipsResult struct {
 	Memberships []Membership `json:"memberships"`
+	TotalCount  int          `json:"total_count"`
 }
 
 type membershipResult struct {
@@ -20,11 +21,25 @@ type membershipRequest struct {
 }
 
 type Membership struct {
-	Id      int      `json:"id"`
-	Project IdName   `json:"project"`
-	User    IdName   `json:"user"`
-	Roles   []IdName `json:"roles"`
-	Groups  []IdName `json:"groups"`
+	Id      int              `json:"id"`
+	Project IdName           `json:"project"`
+	User    IdName           `json:"user"`
+	Group   *IdName          `json:"group,omitempty"`
+	Roles   []MembershipRole `json:"roles"`
+	Groups  []IdName         `json:"groups"`
+
+	// Only used to create and update memberships. UserId may be the id of
+	// a group.
+	UserId  int   `json:"user_id,omitempty"`
+	RoleIds []int `json:"role_ids,omitempty"`
+}
+
+// MembershipRole is a role of a membership. Inherited roles come from a
+// group or from the parent project, and can't be given directly.
+type MembershipRole struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	Inherited bool   `json:"inherited,omitempty"`
 }
 
 func (c *Client) Memberships(projectId int) ([]Membership, error) {
@@ -54,6 +69,23 @@ func (c *Client) Memberships(projectId int) ([]Membership, error) {
 	return r.Memberships, nil
 }
 
+// allMemberships fetches every page of the memberships of the project.
+func (c *Client) allMemberships(projectId int) ([]Membership, error) {
+	var memberships []Membership
+	err := c.getAllPages("/projects/"+strconv.Itoa(projectId)+"/memberships.json", func(decoder *json.Decoder) (int, int, error) {
+		var r membershipsResult
+		if err := decoder.Decode(&r); err != nil {
+			return 0, 0, err
+		}
+		memberships = append(memberships, r.Memberships...)
+		return len(r.Memberships), r.TotalCount, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
 func (c *Client) Membership(id int) (*Membership, error) {
 	req, err := c.NewRequest("GET", "/memberships/"+strconv.Itoa(id)+".json", nil)
 	if err != nil {
@@ -88,7 +120,7 @@ func (c *Client) CreateMembership(membership Membership) (*Membership, error) {
 	if err != nil {
 		return nil, err
 	}
-	req, err := c.NewRequest("POST", "/memberships.json", strings.NewReader(string(s)))
+	req, err := c.NewRequest("POST", "/projects/"+strconv.Itoa(membership.Project.Id)+"/memberships.json", strings.NewReader(string(s)))
 	if err != nil {
 		return nil, err
 	}
@@ -3,6 +3,7 @@ package redmine
 import (
 	"encoding/json"
 	"errors"
+	"net/url"
 	"strconv"
 	"strings"
 )
@@ -16,22 +17,76 @@ type projectResult struct {
 }
 
 type projectsResult struct {
-	Projects []Project `json:"projects"`
+	Projects   []Project `json:"projects"`
+	TotalCount int       `json:"total_count"`
 }
 
 type Project struct {
-	Id           int            `json:"id"`
-	Parent       IdName         `json:"parent"`
-	Name         string         `json:"name"`
-	Identifier   string         `json:"identifier"`
-	Description  string         `json:"description"`
-	CreatedOn    string         `json:"created_on"`
-	UpdatedOn    string         `json:"updated_on"`
-	CustomFields []*CustomField `json:"custom_fields,omitempty"`
+	Id                  int             `json:"id"`
+	Parent              *IdName         `json:"parent,omitempty"`
+	Name                string          `json:"name"`
+	Identifier          string          `json:"identifier"`
+	Description         string          `json:"description"`
+	Homepage            string          `json:"homepage,omitempty"`
+	Status              int             `json:"status,omitempty"`
+	IsPublic            *bool           `json:"is_public,omitempty"`
+	InheritMembers      *bool           `json:"inherit_members,omitempty"`
+	CreatedOn           string          `json:"created_on"`
+	UpdatedOn           string          `json:"updated_on"`
+	CustomFields        CustomFieldList `json:"custom_fields,omitempty"`
+	Trackers            []IdName        `json:"trackers,omitempty"`
+	IssueCategories     []IdName        `json:"issue_categories,omitempty"`
+	EnabledModules      []IdName        `json:"enabled_modules,omitempty"`
+	TimeEntryActivities []IdName        `json:"time_entry_activities,omitempty"`
+	IssueCustomFields   []IdName        `json:"issue_custom_fields,omitempty"`
+
+	// Only used to create and update projects.
+	ParentId            int      `json:"parent_id,omitempty"`
+	TrackerIds          []int    `json:"tracker_ids,omitempty"`
+	EnabledModuleNames  []string `json:"enabled_module_names,omitempty"`
+	IssueCustomFieldIds []int    `json:"issue_custom_field_ids,omitempty"`
+}
+
+const (
+	ProjectStatusActive   = 1
+	ProjectStatusClosed   = 5
+	ProjectStatusArchived = 9
+)
+
+// ProjectInclude selects associated data returned with projects.
+type ProjectInclude string
+
+const (
+	ProjectIncludeTrackers            ProjectInclude = "trackers"
+	ProjectIncludeIssueCategories     ProjectInclude = "issue_categories"
+	ProjectIncludeEnabledModules      ProjectInclude = "enabled_modules"
+	ProjectIncludeTimeEntryActivities ProjectInclude = "time_entry_activities"
+	ProjectIncludeIssueCustomFields   ProjectInclude = "issue_custom_fields"
+)
+
+func projectIncludeClause(include []ProjectInclude) string {
+	if len(include) == 0 {
+		return ""
+	}
+	names := make([]string, len(include))
+	for i, inc := range include {
+		names[i] = string(inc)
+	}
+	return "include=" + strings.Join(names, ",")
 }
 
-func (c *Client) Project(id int) (*Project, error) {
-	req, err := c.NewRequest("GET", "/projects/"+strconv.Itoa(id)+".json", nil)
+func (c *Client) Project(id int, include ...ProjectInclude) (*Project, error) {
+	return c.getProject(strconv.Itoa(id), include)
+}
+
+// ProjectByIdentifier returns the project with the given identifier, such
+// as "my-project".
+func (c *Client) ProjectByIdentifier(identifier string, include ...ProjectInclude) (*Project, error) {
+	return c.getProject(url.PathEscape(identifier), include)
+}
+
+func (c *Client) getProject(ref string, include []ProjectInclude) (*Project, error) {
+	req, err := c.NewRequest("GET", "/projects/"+ref+".json?"+projectIncludeClause(include), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -43,6 +98,9 @@ func (c *Client) Project(id int) (*Project, error) {
 
 	decoder := json.NewDecoder(res.Body)
 	var r projectResult
+	if res.StatusCode == 404 {
+		return nil, errors.New("Not Found")
+	}
 	if res.StatusCode != 200 {
 		var er errorsResult
 		err = decoder.Decode(&er)
@@ -58,8 +116,8 @@ func (c *Client) Project(id int) (*Project, error) {
 	return &r.Project, nil
 }
 
-func (c *Client) Projects() ([]Project, error) {
-	req, err := c.NewRequest("GET", "/projects.json?"+c.getPaginationClause(), nil)
+func (c *Client) Projects(include ...ProjectInclude) ([]Project, error) {
+	req, err := c.NewRequest("GET", "/projects.json?"+c.getPaginationClause()+"&"+projectIncludeClause(include), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -86,6 +144,23 @@ func (c *Client) Projects() ([]Project, error) {
 	return r.Projects, nil
 }
 
+// allProjects fetches every page of the visible projects.
+func (c *Client) allProjects(include ...ProjectInclude) ([]Project, error) {
+	var projects []Project
+	err := c.getAllPages("/projects.json?"+projectIncludeClause(include), func(decoder *json.Decoder) (int, int, error) {
+		var r projectsResult
+		if err := decoder.Decode(&r); err != nil {
+			return 0, 0, err
+		}
+		projects = append(projects, r.Projects...)
+		return len(r.Projects), r.TotalCount, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
 func (c *Client) CreateProject(project Project) (*Project, error) {
 	var ir projectRequest
 	ir.Project = project
@@ -182,3 +257,92 @@ func (c *Client) DeleteProject(id int) error {
 	}
 	return err
 }
+
+type ProjectsFilter struct {
+	Filter
+}
+
+func NewProjectsFilter() *ProjectsFilter {
+	return &ProjectsFilter{Filter{}}
+}
+
+// Status selects projects by status, one of the ProjectStatus constants.
+// Requires Redmine 5.1 or later.
+func (pf *ProjectsFilter) Status(status int) {
+	pf.AddPair("status", strconv.Itoa(status))
+}
+
+func (pf *ProjectsFilter) Include(include ...ProjectInclude) {
+	pf.AddPair("include", strings.TrimPrefix(projectIncludeClause(include), "include="))
+}
+
+func (c *Client) ProjectsWithFilter(filter *ProjectsFilter) ([]Project, error) {
+	uri, err := c.URLWithFilter("/projects.json", filter.Filter)
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.NewRequest("GET", uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	decoder := json.NewDecoder(res.Body)
+	var r projectsResult
+	if res.StatusCode != 200 {
+		err = errorFromResp(decoder, res.StatusCode)
+	} else {
+		err = decoder.Decode(&r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.Projects, nil
+}
+
+// projectAction sends one of the lifecycle actions of Redmine 5.1 and
+// later to the project.
+func (c *Client) projectAction(id int, action string) error {
+	req, err := c.NewRequest("PUT", "/projects/"+strconv.Itoa(id)+"/"+action+".json", strings.NewReader(""))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return errors.New("Not Found")
+	}
+	if res.StatusCode/100 != 2 {
+		err = errorFromResp(json.NewDecoder(res.Body), res.StatusCode)
+	}
+	return err
+}
+
+// ArchiveProject archives the project, hiding it and its subprojects.
+func (c *Client) ArchiveProject(id int) error {
+	return c.projectAction(id, "archive")
+}
+
+// UnarchiveProject makes an archived project active again.
+func (c *Client) UnarchiveProject(id int) error {
+	return c.projectAction(id, "unarchive")
+}
+
+// CloseProject closes the project, making it read-only.
+func (c *Client) CloseProject(id int) error {
+	return c.projectAction(id, "close")
+}
+
+// ReopenProject makes a closed project active again.
+func (c *Client) ReopenProject(id int) error {
+	return c.projectAction(id, "reopen")
+}
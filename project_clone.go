@@ -0,0 +1,246 @@
+package redmine
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// CloneOptions selects what CloneProject copies besides the project itself.
+type CloneOptions struct {
+	// Modules copies the trackers, enabled modules and issue custom fields
+	// not set in the new project.
+	Modules     bool
+	Categories  bool
+	Versions    bool
+	Memberships bool
+	Wiki        bool
+	// Issues copies the issues with their subtasks, remapping categories
+	// and versions by name.
+	Issues bool
+
+	// Progress is called after each object created, if set.
+	Progress func(step CloneStep)
+}
+
+// CloneStep is an object created by CloneProject. Kind is "project",
+// "category", "version", "membership", "wiki" or "issue"; SourceId is 0 for
+// wiki pages, which are named by Name.
+type CloneStep struct {
+	Kind     string
+	Name     string
+	SourceId int
+	Id       int
+}
+
+// CloneReport lists what CloneProject created, in order.
+type CloneReport struct {
+	Project *Project
+	Steps   []CloneStep
+}
+
+func (r *CloneReport) add(opts *CloneOptions, step CloneStep) {
+	r.Steps = append(r.Steps, step)
+	if opts.Progress != nil {
+		opts.Progress(step)
+	}
+}
+
+// CloneProject creates newProject as a copy of the configuration of the
+// project with the given id, as selected by opts. If anything fails, the
+// new project is deleted, with everything created in it, and the error is
+// returned together with the report of what had been created.
+func (c *Client) CloneProject(sourceId int, newProject Project, opts CloneOptions) (*CloneReport, error) {
+	report := &CloneReport{}
+	source, err := c.Project(sourceId, ProjectIncludeTrackers, ProjectIncludeEnabledModules, ProjectIncludeIssueCustomFields)
+	if err != nil {
+		return report, err
+	}
+	if opts.Modules {
+		if newProject.TrackerIds == nil {
+			for _, t := range source.Trackers {
+				newProject.TrackerIds = append(newProject.TrackerIds, t.Id)
+			}
+		}
+		if newProject.EnabledModuleNames == nil {
+			for _, m := range source.EnabledModules {
+				newProject.EnabledModuleNames = append(newProject.EnabledModuleNames, m.Name)
+			}
+		}
+		if newProject.IssueCustomFieldIds == nil {
+			for _, cf := range source.IssueCustomFields {
+				newProject.IssueCustomFieldIds = append(newProject.IssueCustomFieldIds, cf.Id)
+			}
+		}
+	}
+
+	project, err := c.CreateProject(newProject)
+	if err != nil {
+		return report, err
+	}
+	report.Project = project
+	report.add(&opts, CloneStep{Kind: "project", Name: project.Name, SourceId: sourceId, Id: project.Id})
+
+	if err := c.cloneProjectContents(source.Id, project.Id, &opts, report); err != nil {
+		if derr := c.DeleteProject(project.Id); derr != nil {
+			return report, fmt.Errorf("%w (rollback failed: %v)", err, derr)
+		}
+		return report, err
+	}
+	return report, nil
+}
+
+func (c *Client) cloneProjectContents(sourceId, projectId int, opts *CloneOptions, report *CloneReport) error {
+	// Memberships go first so that categories can keep their assignee.
+	members := map[int]bool{}
+	if opts.Memberships {
+		memberships, err := c.allMemberships(sourceId)
+		if err != nil {
+			return err
+		}
+		for _, m := range memberships {
+			principal := m.User
+			if m.Group != nil {
+				principal = *m.Group
+			}
+			membership := Membership{Project: IdName{Id: projectId}, UserId: principal.Id}
+			for _, r := range m.Roles {
+				// Inherited roles come back with the group or the parent.
+				if !r.Inherited {
+					membership.RoleIds = append(membership.RoleIds, r.Id)
+				}
+			}
+			if principal.Id == 0 || len(membership.RoleIds) == 0 {
+				continue
+			}
+			created, err := c.CreateMembership(membership)
+			if err != nil {
+				return fmt.Errorf("membership of %s: %w", principal.Name, err)
+			}
+			members[principal.Id] = true
+			report.add(opts, CloneStep{Kind: "membership", Name: principal.Name, SourceId: m.Id, Id: created.Id})
+		}
+	}
+
+	if opts.Categories {
+		categories, err := c.IssueCategories(sourceId)
+		if err != nil {
+			return err
+		}
+		for _, cat := range categories {
+			category := IssueCategory{Project: IdName{Id: projectId}, Name: cat.Name}
+			if members[cat.AssignedTo.Id] {
+				category.AssignedToId = cat.AssignedTo.Id
+			}
+			created, err := c.CreateIssueCategory(category)
+			if err != nil {
+				return fmt.Errorf("category %s: %w", cat.Name, err)
+			}
+			report.add(opts, CloneStep{Kind: "category", Name: cat.Name, SourceId: cat.Id, Id: created.Id})
+		}
+	}
+
+	if opts.Versions {
+		versions, err := c.Versions(sourceId)
+		if err != nil {
+			return err
+		}
+		for _, v := range versions {
+			// Versions shared by other projects are listed too.
+			if v.Project.Id != sourceId {
+				continue
+			}
+			version := Version{
+				Project:      IdName{Id: projectId},
+				Name:         v.Name,
+				Description:  v.Description,
+				Status:       v.Status,
+				DueDate:      v.DueDate,
+				CustomFields: v.CustomFields,
+			}
+			created, err := c.CreateVersion(version)
+			if err != nil {
+				return fmt.Errorf("version %s: %w", v.Name, err)
+			}
+			report.add(opts, CloneStep{Kind: "version", Name: v.Name, SourceId: v.Id, Id: created.Id})
+		}
+	}
+
+	if opts.Wiki {
+		if err := c.cloneWiki(sourceId, projectId, opts, report); err != nil {
+			return err
+		}
+	}
+
+	if opts.Issues {
+		issues, err := c.IssuesByFilter(&IssueFilter{ProjectId: strconv.Itoa(sourceId), StatusId: "*"})
+		if err != nil {
+			return err
+		}
+		for _, root := range BuildIssueForest(issues, nil) {
+			// Subprojects' issues may be listed too.
+			if root.Issue.Project != nil && root.Issue.Project.Id != sourceId {
+				continue
+			}
+			copied, err := c.CopyIssue(root.Issue.Id, CopyOptions{ProjectId: projectId, Subtasks: true, Watchers: true})
+			if err != nil {
+				return fmt.Errorf("issue #%d: %w", root.Issue.Id, err)
+			}
+			ids := make([]int, 0, len(copied.Issues))
+			for id := range copied.Issues {
+				ids = append(ids, id)
+			}
+			sort.Ints(ids)
+			for _, id := range ids {
+				report.add(opts, CloneStep{Kind: "issue", SourceId: id, Id: copied.Issues[id]})
+			}
+		}
+	}
+	return nil
+}
+
+// cloneWiki copies the wiki pages, parents first.
+func (c *Client) cloneWiki(sourceId, projectId int, opts *CloneOptions, report *CloneReport) error {
+	pages, err := c.WikiPages(sourceId)
+	if err != nil {
+		return err
+	}
+	byTitle := make(map[string]WikiPage, len(pages))
+	for _, p := range pages {
+		byTitle[p.Title] = p
+	}
+	done := map[string]bool{}
+	var clone func(title string) error
+	clone = func(title string) error {
+		if done[title] {
+			return nil
+		}
+		done[title] = true
+		p := byTitle[title]
+		parent := ""
+		if p.Parent != nil {
+			if _, ok := byTitle[p.Parent.Title]; ok {
+				parent = p.Parent.Title
+				if err := clone(parent); err != nil {
+					return err
+				}
+			}
+		}
+		full, err := c.WikiPage(sourceId, title)
+		if err != nil {
+			return fmt.Errorf("wiki page %s: %w", title, err)
+		}
+		page := WikiPage{Title: title, Text: full.Text, Comments: full.Comments, ParentTitle: parent}
+		if _, err := c.CreateWikiPage(projectId, page); err != nil {
+			return fmt.Errorf("wiki page %s: %w", title, err)
+		}
+		report.add(opts, CloneStep{Kind: "wiki", Name: title})
+		return nil
+	}
+	for _, p := range pages {
+		if err := clone(p.Title); err != nil {
+			return err
+		}
+	}
+	return nil
+}
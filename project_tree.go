@@ -0,0 +1,172 @@
+package redmine
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+)
+
+// ProjectNode is a project of the project hierarchy.
+type ProjectNode struct {
+	Project  *Project
+	Parent   *ProjectNode
+	Children []*ProjectNode
+}
+
+// ProjectHierarchy is the parent/child forest formed by projects.
+type ProjectHierarchy struct {
+	Roots []*ProjectNode
+
+	nodes map[int]*ProjectNode
+}
+
+// ProjectTree fetches all visible projects and returns their hierarchy.
+// Projects whose parent is not visible, such as archived ones, are roots.
+func (c *Client) ProjectTree() (*ProjectHierarchy, error) {
+	projects, err := c.allProjects()
+	if err != nil {
+		return nil, err
+	}
+	return BuildProjectHierarchy(projects), nil
+}
+
+// BuildProjectHierarchy assembles projects into parent/child trees. Roots
+// and children are ordered by name.
+func BuildProjectHierarchy(projects []Project) *ProjectHierarchy {
+	h := &ProjectHierarchy{nodes: make(map[int]*ProjectNode, len(projects))}
+	for i := range projects {
+		h.nodes[projects[i].Id] = &ProjectNode{Project: &projects[i]}
+	}
+	for i := range projects {
+		node := h.nodes[projects[i].Id]
+		if p := projects[i].Parent; p != nil {
+			if parent, ok := h.nodes[p.Id]; ok && parent != node {
+				node.Parent = parent
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		h.Roots = append(h.Roots, node)
+	}
+	sortProjectNodes(h.Roots)
+	for _, node := range h.nodes {
+		sortProjectNodes(node.Children)
+	}
+	return h
+}
+
+func sortProjectNodes(nodes []*ProjectNode) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Project.Name < nodes[j].Project.Name })
+}
+
+// Node returns the node of the project with the given id, or nil.
+func (h *ProjectHierarchy) Node(id int) *ProjectNode {
+	return h.nodes[id]
+}
+
+// Walk calls fn for every project in depth-first order, with the depth of
+// each project in the hierarchy.
+func (h *ProjectHierarchy) Walk(fn func(node *ProjectNode, depth int)) {
+	for _, root := range h.Roots {
+		root.Walk(fn)
+	}
+}
+
+// Walk calls fn for the node and all of its descendants in depth-first
+// order, with the depth of each node relative to n.
+func (n *ProjectNode) Walk(fn func(node *ProjectNode, depth int)) {
+	n.walk(fn, 0)
+}
+
+func (n *ProjectNode) walk(fn func(node *ProjectNode, depth int), depth int) {
+	fn(n, depth)
+	for _, child := range n.Children {
+		child.walk(fn, depth+1)
+	}
+}
+
+// Descendants returns the projects below the one with the given id, in
+// depth-first order.
+func (h *ProjectHierarchy) Descendants(id int) []*Project {
+	node := h.nodes[id]
+	if node == nil {
+		return nil
+	}
+	var projects []*Project
+	node.Walk(func(n *ProjectNode, depth int) {
+		if depth > 0 {
+			projects = append(projects, n.Project)
+		}
+	})
+	return projects
+}
+
+// Ancestors returns the projects above the one with the given id, from the
+// root down to its parent.
+func (h *ProjectHierarchy) Ancestors(id int) []*Project {
+	node := h.nodes[id]
+	if node == nil {
+		return nil
+	}
+	var projects []*Project
+	for p := node.Parent; p != nil; p = p.Parent {
+		projects = append([]*Project{p.Project}, projects...)
+	}
+	return projects
+}
+
+// SubtreeIds returns the id of the project with the given id followed by the
+// ids of its descendants.
+func (h *ProjectHierarchy) SubtreeIds(id int) []int {
+	if h.nodes[id] == nil {
+		return nil
+	}
+	ids := []int{id}
+	for _, p := range h.Descendants(id) {
+		ids = append(ids, p.Id)
+	}
+	return ids
+}
+
+// SubtreeIssues returns the issues of the project with the given id and of
+// all its subprojects that match filter, which may be nil to list open
+// issues. The project of filter is ignored.
+func (c *Client) SubtreeIssues(projectId int, filter *IssueFilter) ([]Issue, error) {
+	f := IssueFilter{}
+	if filter != nil {
+		f = *filter
+	}
+	f.ProjectId = strconv.Itoa(projectId)
+	f.SubprojectId = "*"
+	return c.IssuesByFilter(&f)
+}
+
+// SubtreeTimeEntries returns the time entries of the project with the given
+// id and of all its subprojects.
+func (c *Client) SubtreeTimeEntries(projectId int) ([]TimeEntry, error) {
+	h, err := c.ProjectTree()
+	if err != nil {
+		return nil, err
+	}
+	ids := h.SubtreeIds(projectId)
+	if ids == nil {
+		return nil, errors.New("Not Found")
+	}
+	var entries []TimeEntry
+	seen := map[int]bool{}
+	for _, id := range ids {
+		// Depending on the settings, entries of subprojects are listed
+		// with their parent too.
+		projectEntries, err := c.allTimeEntries(*NewFilter("project_id", strconv.Itoa(id)))
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range projectEntries {
+			if !seen[e.Id] {
+				seen[e.Id] = true
+				entries = append(entries, e)
+			}
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,54 @@
+package redmine
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+type queriesResult struct {
+	Queries    []Query `json:"queries"`
+	TotalCount int     `json:"total_count"`
+}
+
+// Query is a saved issue query.
+// ProjectId is 0 for queries that are available in all projects.
+type Query struct {
+	Id        int    `json:"id"`
+	Name      string `json:"name"`
+	IsPublic  bool   `json:"is_public"`
+	ProjectId int    `json:"project_id"`
+}
+
+// Queries fetches the saved queries visible to the current user, reading
+// every page.
+func (c *Client) Queries() ([]Query, error) {
+	var queries []Query
+	err := c.getAllPages("/queries.json", func(decoder *json.Decoder) (int, int, error) {
+		var r queriesResult
+		if err := decoder.Decode(&r); err != nil {
+			return 0, 0, err
+		}
+		queries = append(queries, r.Queries...)
+		return len(r.Queries), r.TotalCount, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
+
+// QueryByName fetches the saved query with the given name.
+// Names are matched case-insensitively and the first match is returned.
+func (c *Client) QueryByName(name string) (*Query, error) {
+	queries, err := c.Queries()
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range queries {
+		if strings.EqualFold(q.Name, name) {
+			return &q, nil
+		}
+	}
+	return nil, errors.New("Not Found")
+}
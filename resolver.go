@@ -0,0 +1,193 @@
+package redmine
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Resolver maps the names people use to the ids the API expects: project
+// identifiers, user logins and tracker, status, priority and time entry
+// activity names. Results are cached; numeric values are taken as ids.
+//
+// Methods taking a project id accept the id returned by ProjectId, so
+// versions, wiki pages, memberships and issues can be looked up from a
+// project identifier. IssueFilter.ProjectId accepts identifiers as is.
+type Resolver struct {
+	Client *Client
+
+	mu         sync.Mutex
+	projects   map[string]int
+	users      map[string]int
+	trackers   map[int]string
+	statuses   map[int]string
+	priorities map[int]string
+	activities map[int]string
+}
+
+func NewResolver(c *Client) *Resolver {
+	return &Resolver{Client: c}
+}
+
+// Reset drops the cached names.
+func (r *Resolver) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.projects = nil
+	r.users = nil
+	r.trackers = nil
+	r.statuses = nil
+	r.priorities = nil
+	r.activities = nil
+}
+
+// ProjectId returns the id of the project with the given identifier, or
+// name if no project has that identifier.
+func (r *Resolver) ProjectId(ref string) (int, error) {
+	if id, err := strconv.Atoi(ref); err == nil {
+		return id, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, ok := r.projects[ref]; ok {
+		return id, nil
+	}
+	if r.projects == nil {
+		r.projects = map[string]int{}
+	}
+	project, err := r.Client.ProjectByIdentifier(ref)
+	if err == nil {
+		r.projects[ref] = project.Id
+		return project.Id, nil
+	}
+	projects, err := r.Client.allProjects()
+	if err != nil {
+		return 0, err
+	}
+	for _, p := range projects {
+		if strings.EqualFold(p.Name, ref) {
+			r.projects[ref] = p.Id
+			return p.Id, nil
+		}
+	}
+	return 0, errors.New("Unknown project " + ref)
+}
+
+// UserId returns the id of the user with the given login, or of the
+// current user for "me". Looking up other users requires administrator
+// rights.
+func (r *Resolver) UserId(login string) (int, error) {
+	if id, err := strconv.Atoi(login); err == nil {
+		return id, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id, ok := r.users[login]; ok {
+		return id, nil
+	}
+	if r.users == nil {
+		r.users = map[string]int{}
+	}
+	if login == "me" {
+		user, err := r.Client.MyAccount()
+		if err != nil {
+			return 0, err
+		}
+		r.users[login] = user.Id
+		return user.Id, nil
+	}
+	filter := NewUsersFilter()
+	filter.Name(login)
+	users, err := r.Client.UsersWithFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+	for _, u := range users {
+		if strings.EqualFold(u.Login, login) {
+			r.users[login] = u.Id
+			return u.Id, nil
+		}
+	}
+	return 0, errors.New("Unknown user " + login)
+}
+
+// lookup resolves name in the cached names, loading them with load first.
+func (r *Resolver) lookup(m *map[int]string, load func() (map[int]string, error), what, name string) (int, error) {
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if *m == nil {
+		names, err := load()
+		if err != nil {
+			return 0, err
+		}
+		*m = names
+	}
+	if id, ok := lookupName(*m, name); ok {
+		return id, nil
+	}
+	return 0, errors.New("Unknown " + what + " " + name)
+}
+
+// TrackerId returns the id of the tracker with the given name.
+func (r *Resolver) TrackerId(name string) (int, error) {
+	return r.lookup(&r.trackers, func() (map[int]string, error) {
+		trackers, err := r.Client.Trackers()
+		if err != nil {
+			return nil, err
+		}
+		m := map[int]string{}
+		for _, t := range trackers {
+			m[t.Id] = t.Name
+		}
+		return m, nil
+	}, "tracker", name)
+}
+
+// StatusId returns the id of the issue status with the given name.
+func (r *Resolver) StatusId(name string) (int, error) {
+	return r.lookup(&r.statuses, func() (map[int]string, error) {
+		statuses, err := r.Client.IssueStatuses()
+		if err != nil {
+			return nil, err
+		}
+		m := map[int]string{}
+		for _, s := range statuses {
+			m[s.Id] = s.Name
+		}
+		return m, nil
+	}, "status", name)
+}
+
+// PriorityId returns the id of the issue priority with the given name.
+func (r *Resolver) PriorityId(name string) (int, error) {
+	return r.lookup(&r.priorities, func() (map[int]string, error) {
+		priorities, err := r.Client.IssuePriorities()
+		if err != nil {
+			return nil, err
+		}
+		m := map[int]string{}
+		for _, p := range priorities {
+			m[p.Id] = p.Name
+		}
+		return m, nil
+	}, "priority", name)
+}
+
+// ActivityId returns the id of the time entry activity with the given name.
+func (r *Resolver) ActivityId(name string) (int, error) {
+	return r.lookup(&r.activities, func() (map[int]string, error) {
+		activities, err := r.Client.TimeEntryActivities()
+		if err != nil {
+			return nil, err
+		}
+		m := map[int]string{}
+		for _, a := range activities {
+			m[a.Id] = a.Name
+		}
+		return m, nil
+	}, "activity", name)
+}
@@ -0,0 +1,115 @@
+package redmine
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	SearchScopeAll         string = "all"
+	SearchScopeMyProjects  string = "my_projects"
+	SearchScopeSubprojects string = "subprojects"
+)
+
+const (
+	SearchTypeIssues     string = "issues"
+	SearchTypeNews       string = "news"
+	SearchTypeDocuments  string = "documents"
+	SearchTypeChangesets string = "changesets"
+	SearchTypeWikiPages  string = "wiki_pages"
+	SearchTypeMessages   string = "messages"
+	SearchTypeProjects   string = "projects"
+)
+
+// SearchOptions narrows down a search.
+// The zero value searches all resource types of all visible projects.
+type SearchOptions struct {
+	ProjectId  int      // search only within this project if non-zero
+	Scope      string   // one of the SearchScope* constants
+	Types      []string // SearchType* constants; empty means all types
+	AllWords   bool
+	TitlesOnly bool
+	OpenIssues bool
+}
+
+type SearchResult struct {
+	Id          int    `json:"id"`
+	Title       string `json:"title"`
+	Type        string `json:"type"`
+	Url         string `json:"url"`
+	Description string `json:"description"`
+	Datetime    string `json:"datetime"`
+}
+
+// SearchResults is one page of search results.
+// Use Client.Limit and Client.Offset to walk through the pages.
+type SearchResults struct {
+	Results    []SearchResult `json:"results"`
+	TotalCount int            `json:"total_count"`
+	Offset     int            `json:"offset"`
+	Limit      int            `json:"limit"`
+}
+
+// Search runs a full text search for query.
+// opts may be nil.
+func (c *Client) Search(query string, opts *SearchOptions) (*SearchResults, error) {
+	if opts == nil {
+		opts = &SearchOptions{}
+	}
+	path := "/search.json"
+	if opts.ProjectId != 0 {
+		path = "/projects/" + strconv.Itoa(opts.ProjectId) + "/search.json"
+	}
+	req, err := c.NewRequest("GET", path+"?"+getSearchClause(query, opts)+"&"+c.getPaginationClause(), nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	decoder := json.NewDecoder(res.Body)
+	var r SearchResults
+	if res.StatusCode == 404 {
+		return nil, errors.New("Not Found")
+	}
+	if res.StatusCode != 200 {
+		var er errorsResult
+		err = decoder.Decode(&er)
+		if err == nil {
+			err = errors.New(strings.Join(er.Errors, "\n"))
+		}
+	} else {
+		err = decoder.Decode(&r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func getSearchClause(query string, opts *SearchOptions) string {
+	v := url.Values{}
+	v.Set("q", query)
+	if opts.Scope != "" {
+		v.Set("scope", opts.Scope)
+	}
+	for _, t := range opts.Types {
+		v.Set(t, "1")
+	}
+	if opts.AllWords {
+		v.Set("all_words", "1")
+	}
+	if opts.TitlesOnly {
+		v.Set("titles_only", "1")
+	}
+	if opts.OpenIssues {
+		v.Set("open_issues", "1")
+	}
+	return v.Encode()
+}
@@ -9,6 +9,7 @@ import (
 
 type timeEntriesResult struct {
 	TimeEntries []TimeEntry `json:"time_entries"`
+	TotalCount  int         `json:"total_count"`
 }
 
 type timeEntryResult struct {
@@ -20,17 +21,17 @@ type timeEntryRequest struct {
 }
 
 type TimeEntry struct {
-	Id           int            `json:"id"`
-	Project      IdName         `json:"project"`
-	Issue        Id             `json:"issue"`
-	User         IdName         `json:"user"`
-	Activity     IdName         `json:"activity"`
-	Hours        float32        `json:"hours"`
-	Comments     string         `json:"comments"`
-	SpentOn      string         `json:"spent_on"`
-	CreatedOn    string         `json:"created_on"`
-	UpdatedOn    string         `json:"updated_on"`
-	CustomFields []*CustomField `json:"custom_fields,omitempty"`
+	Id           int             `json:"id"`
+	Project      IdName          `json:"project"`
+	Issue        Id              `json:"issue"`
+	User         IdName          `json:"user"`
+	Activity     IdName          `json:"activity"`
+	Hours        float32         `json:"hours"`
+	Comments     string          `json:"comments"`
+	SpentOn      string          `json:"spent_on"`
+	CreatedOn    string          `json:"created_on"`
+	UpdatedOn    string          `json:"updated_on"`
+	CustomFields CustomFieldList `json:"custom_fields,omitempty"`
 }
 
 // TimeEntriesWithFilter send query and return parsed result
@@ -69,6 +70,24 @@ func (c *Client) TimeEntriesWithFilter(filter Filter) ([]TimeEntry, error) {
 	return r.TimeEntries, nil
 }
 
+// allTimeEntries fetches every page of the time entries matching filter.
+func (c *Client) allTimeEntries(filter Filter) ([]TimeEntry, error) {
+	var entries []TimeEntry
+	path := "/time_entries.json?" + strings.TrimPrefix(filter.ToURLParams(), "&")
+	err := c.getAllPages(path, func(decoder *json.Decoder) (int, int, error) {
+		var r timeEntriesResult
+		if err := decoder.Decode(&r); err != nil {
+			return 0, 0, err
+		}
+		entries = append(entries, r.TimeEntries...)
+		return len(r.TimeEntries), r.TotalCount, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
 func (c *Client) TimeEntries(projectId int) ([]TimeEntry, error) {
 	req, err := c.NewRequest("GET", "/projects/"+strconv.Itoa(projectId)+"/time_entries.json"+c.getPaginationClause(), nil)
 	if err != nil {
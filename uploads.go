@@ -1,10 +1,20 @@
 package redmine
 
 import (
-	"bytes"
+	"bufio"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"io/ioutil"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -17,17 +27,72 @@ type Upload struct {
 	Token       string `json:"token"`
 	Filename    string `json:"filename"`
 	ContentType string `json:"content_type"`
+	Description string `json:"description,omitempty"`
 }
 
+// UploadOptions controls how UploadReader sends the content.
+type UploadOptions struct {
+	// ContentType of the file. It is guessed from the filename
+	// extension or the first bytes of the content if empty.
+	ContentType string
+
+	// Progress, if not nil, is called after each chunk is sent with the
+	// number of bytes sent so far and the total size (-1 if unknown).
+	Progress func(sent, total int64)
+}
+
+// Upload uploads the given file and returns the token to attach it.
 func (c *Client) Upload(filename string) (*Upload, error) {
-	content, err := ioutil.ReadFile(filename)
+	f, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
-	req, err := c.NewRequest("POST", "/uploads.json", bytes.NewBuffer(content))
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return c.UploadReader(f, filepath.Base(filename), fi.Size(), nil)
+}
+
+// UploadReader streams the content of r to Redmine under the given filename.
+// size is the length of the content, or -1 if unknown.
+// opts may be nil.
+// The token returned by Redmine is checked against the digest of the
+// content sent.
+func (c *Client) UploadReader(r io.Reader, filename string, size int64, opts *UploadOptions) (*Upload, error) {
+	if opts == nil {
+		opts = &UploadOptions{}
+	}
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(filename))
+	}
+	if contentType == "" {
+		br := bufio.NewReader(r)
+		head, _ := br.Peek(512)
+		contentType = http.DetectContentType(head)
+		r = br
+	}
+
+	sha := sha256.New()
+	md := md5.New()
+	body := &progressReader{
+		r:        io.TeeReader(r, io.MultiWriter(sha, md)),
+		total:    size,
+		progress: opts.Progress,
+	}
+
+	v := url.Values{}
+	v.Set("filename", filename)
+	v.Set("content_type", contentType)
+	req, err := c.NewRequest("POST", "/uploads.json?"+v.Encode(), body)
 	if err != nil {
 		return nil, err
 	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
 	req.Header.Set("Content-Type", "application/octet-stream")
 	res, err := c.Do(req)
 	if err != nil {
@@ -36,18 +101,60 @@ func (c *Client) Upload(filename string) (*Upload, error) {
 	defer res.Body.Close()
 
 	decoder := json.NewDecoder(res.Body)
-	var r uploadResponse
+	var ur uploadResponse
 	if res.StatusCode != 201 {
-		var er errorsResult
-		err = decoder.Decode(&er)
-		if err == nil {
-			err = errors.New(strings.Join(er.Errors, "\n"))
-		}
+		err = errorFromResp(decoder, res.StatusCode)
 	} else {
-		err = decoder.Decode(&r)
+		err = decoder.Decode(&ur)
 	}
 	if err != nil {
 		return nil, err
 	}
-	return &r.Upload, nil
+	if err := verifyUploadToken(&ur.Upload, sha, md); err != nil {
+		return nil, err
+	}
+	ur.Upload.Filename = filename
+	ur.Upload.ContentType = contentType
+	return &ur.Upload, nil
+}
+
+// verifyUploadToken checks the "<id>.<digest>" token returned by Redmine.
+// Redmine 3.4 and later use SHA-256 digests, older versions use MD5.
+func verifyUploadToken(upload *Upload, sha, md hash.Hash) error {
+	parts := strings.SplitN(upload.Token, ".", 2)
+	if upload.Token == "" || (upload.Id != 0 && parts[0] != strconv.Itoa(upload.Id)) {
+		return errors.New("Invalid upload token: " + upload.Token)
+	}
+	if len(parts) == 1 {
+		return nil
+	}
+	var sum []byte
+	switch len(parts[1]) {
+	case sha256.Size * 2:
+		sum = sha.Sum(nil)
+	case md5.Size * 2:
+		sum = md.Sum(nil)
+	default:
+		return nil
+	}
+	if !strings.EqualFold(parts[1], hex.EncodeToString(sum)) {
+		return errors.New("Upload digest mismatch")
+	}
+	return nil
+}
+
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress func(sent, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.sent += int64(n)
+	if p.progress != nil && n > 0 {
+		p.progress(p.sent, p.total)
+	}
+	return n, err
 }
@@ -16,15 +16,34 @@ type usersResult struct {
 }
 
 type User struct {
-	Id           int            `json:"id"`
-	Login        string         `json:"login"`
-	Firstname    string         `json:"firstname"`
-	Lastname     string         `json:"lastname"`
-	Mail         string         `json:"mail"`
-	CreatedOn    string         `json:"created_on"`
-	LatLoginOn   string         `json:"last_login_on"`
-	Memberships  []Membership   `json:"memberships"`
-	CustomFields []*CustomField `json:"custom_fields,omitempty"`
+	Id           int             `json:"id"`
+	Login        string          `json:"login"`
+	Admin        *bool           `json:"admin,omitempty"`
+	Firstname    string          `json:"firstname"`
+	Lastname     string          `json:"lastname"`
+	Mail         string          `json:"mail"`
+	Status       int             `json:"status,omitempty"` // 1 active, 2 registered, 3 locked
+	ApiKey       string          `json:"api_key,omitempty"`
+	TwofaScheme  string          `json:"twofa_scheme,omitempty"`
+	AuthSourceId int             `json:"auth_source_id,omitempty"`
+	CreatedOn    string          `json:"created_on"`
+	LatLoginOn   string          `json:"last_login_on"`
+	Memberships  []Membership    `json:"memberships"`
+	Groups       []IdName        `json:"groups,omitempty"`
+	CustomFields CustomFieldList `json:"custom_fields,omitempty"`
+
+	// Only used to create and update users.
+	Password         string `json:"password,omitempty"`
+	GeneratePassword bool   `json:"generate_password,omitempty"`
+	MustChangePasswd *bool  `json:"must_change_passwd,omitempty"`
+	MailNotification string `json:"mail_notification,omitempty"`
+	// SendInformation mails the account information to the user.
+	SendInformation bool `json:"-"`
+}
+
+type userRequest struct {
+	User            User `json:"user"`
+	SendInformation bool `json:"send_information,omitempty"`
 }
 
 type UsersFilter struct {
@@ -219,3 +238,125 @@ func (c *Client) MyAccount() (*User, error) {
 	}
 	return &r.User, nil
 }
+
+func (c *Client) CreateUser(user User) (*User, error) {
+	s, err := json.Marshal(userRequest{User: user, SendInformation: user.SendInformation})
+	if err != nil {
+		return nil, err
+	}
+	req, err := c.NewRequest("POST", "/users.json", strings.NewReader(string(s)))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	decoder := json.NewDecoder(res.Body)
+	var r userResult
+	if res.StatusCode != 201 {
+		err = errorFromResp(decoder, res.StatusCode)
+	} else {
+		err = decoder.Decode(&r)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r.User, nil
+}
+
+// UpdateUser changes the fields of the user that are set, leaving the
+// others as they are, so user may hold only the fields to change.
+func (c *Client) UpdateUser(user User) error {
+	fields := map[string]interface{}{}
+	set := func(name string, value interface{}, ok bool) {
+		if ok {
+			fields[name] = value
+		}
+	}
+	set("login", user.Login, user.Login != "")
+	set("firstname", user.Firstname, user.Firstname != "")
+	set("lastname", user.Lastname, user.Lastname != "")
+	set("mail", user.Mail, user.Mail != "")
+	set("admin", user.Admin, user.Admin != nil)
+	set("status", user.Status, user.Status != 0)
+	set("auth_source_id", user.AuthSourceId, user.AuthSourceId != 0)
+	set("custom_fields", user.CustomFields, user.CustomFields != nil)
+	set("password", user.Password, user.Password != "")
+	set("generate_password", user.GeneratePassword, user.GeneratePassword)
+	set("must_change_passwd", user.MustChangePasswd, user.MustChangePasswd != nil)
+	set("mail_notification", user.MailNotification, user.MailNotification != "")
+	body := map[string]interface{}{"user": fields}
+	if user.SendInformation {
+		body["send_information"] = true
+	}
+	s, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return c.putUser(user.Id, s)
+}
+
+func (c *Client) putUser(id int, body []byte) error {
+	req, err := c.NewRequest("PUT", "/users/"+strconv.Itoa(id)+".json", strings.NewReader(string(body)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return errors.New("Not Found")
+	}
+	if res.StatusCode/100 != 2 {
+		err = errorFromResp(json.NewDecoder(res.Body), res.StatusCode)
+	}
+	return err
+}
+
+// setUserStatus changes the status of the user only.
+func (c *Client) setUserStatus(id int, status int) error {
+	s, err := json.Marshal(map[string]map[string]int{"user": {"status": status}})
+	if err != nil {
+		return err
+	}
+	return c.putUser(id, s)
+}
+
+// LockUser locks the account of the user, who can no longer log in.
+func (c *Client) LockUser(id int) error {
+	return c.setUserStatus(id, 3)
+}
+
+// UnlockUser makes the account of the user active again.
+func (c *Client) UnlockUser(id int) error {
+	return c.setUserStatus(id, 1)
+}
+
+func (c *Client) DeleteUser(id int) error {
+	req, err := c.NewRequest("DELETE", "/users/"+strconv.Itoa(id)+".json", strings.NewReader(""))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == 404 {
+		return errors.New("Not Found")
+	}
+	if res.StatusCode/100 != 2 {
+		err = errorFromResp(json.NewDecoder(res.Body), res.StatusCode)
+	}
+	return err
+}
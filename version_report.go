@@ -0,0 +1,191 @@
+package redmine
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// VersionDay is the state of the issues of a version at the end of a day.
+type VersionDay struct {
+	Date string `json:"date"`
+	// Statuses maps status ids to the number of issues in that status.
+	Statuses map[int]int `json:"statuses"`
+	Open     int         `json:"open"`
+	Closed   int         `json:"closed"`
+	// RemainingHours is the estimated hours of the open issues.
+	RemainingHours float64 `json:"remaining_hours"`
+}
+
+// VersionReport holds the daily series of a version, usable both as
+// cumulative flow (Statuses) and as burndown (RemainingHours, Open).
+type VersionReport struct {
+	VersionId int          `json:"version_id"`
+	Days      []VersionDay `json:"days"`
+}
+
+// VersionReportOptions bounds the report. Zero values default to the
+// creation date of the version and the earlier of its due date and today.
+type VersionReportOptions struct {
+	From time.Time
+	To   time.Time
+}
+
+// VersionReport fetches the issues of the given version with their journals
+// and reconstructs its daily cumulative flow and burndown. Issues of the
+// version's project changed since the start of the report are fetched too,
+// so that those moved out of the version are counted on the days they were
+// part of it; issues moved to other projects are not found. opts may be nil.
+func (c *Client) VersionReport(versionId int, opts *VersionReportOptions) (*VersionReport, error) {
+	if opts == nil {
+		opts = &VersionReportOptions{}
+	}
+	version, err := c.Version(versionId)
+	if err != nil {
+		return nil, err
+	}
+	from, to := opts.From, opts.To
+	if from.IsZero() {
+		if from, err = parseRedmineTime(version.CreatedOn); err != nil {
+			return nil, err
+		}
+	}
+	if to.IsZero() {
+		to = time.Now()
+		if due, err := time.ParseInLocation("2006-01-02", version.DueDate, time.Local); err == nil && due.Before(to) {
+			to = due
+		}
+	}
+	statuses, err := c.IssueStatuses()
+	if err != nil {
+		return nil, err
+	}
+	issues, err := c.IssuesWithJournals(&IssueFilter{
+		StatusId:     "*",
+		ExtraFilters: map[string]string{"fixed_version_id": strconv.Itoa(versionId)},
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Issues moved out of the version were updated since.
+	changed, err := c.IssuesWithJournals(&IssueFilter{
+		ProjectId: strconv.Itoa(version.Project.Id),
+		StatusId:  "*",
+		UpdatedOn: url.QueryEscape(">=" + from.Format("2006-01-02")),
+	})
+	if err != nil {
+		return nil, err
+	}
+	seen := map[int]bool{}
+	for _, issue := range issues {
+		seen[issue.Id] = true
+	}
+	for _, issue := range changed {
+		if !seen[issue.Id] {
+			issues = append(issues, issue)
+		}
+	}
+	return BuildVersionReport(versionId, issues, ClosedStatusIds(statuses), from, to)
+}
+
+// BuildVersionReport computes the report of the given version from issues
+// fetched with their journals, one day at a time from from to to. Issues
+// are counted on the days they were part of the version, so issues not in
+// it are ignored.
+func BuildVersionReport(versionId int, issues []Issue, closedStatuses []int, from, to time.Time) (*VersionReport, error) {
+	if to.Before(from) {
+		return nil, errors.New("Invalid report range")
+	}
+	version := strconv.Itoa(versionId)
+	r := &VersionReport{VersionId: versionId}
+	var ends []time.Time
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	for day := start; !day.After(to); day = day.AddDate(0, 0, 1) {
+		ends = append(ends, day.AddDate(0, 0, 1).Add(-time.Nanosecond))
+		r.Days = append(r.Days, VersionDay{
+			Date:     day.Format("2006-01-02"),
+			Statuses: map[int]int{},
+		})
+	}
+	for i := range issues {
+		created, err := parseRedmineTime(issues[i].CreatedOn)
+		if err != nil {
+			return nil, err
+		}
+		states, err := IssueStates(&issues[i])
+		if err != nil {
+			return nil, err
+		}
+		_, times, err := sortedJournals(&issues[i])
+		if err != nil {
+			return nil, err
+		}
+		// states[k+1] holds from times[k] on.
+		k := 0
+		for n, end := range ends {
+			if created.After(end) {
+				continue
+			}
+			for k < len(times) && !times[k].After(end) {
+				k++
+			}
+			s := states[k]
+			if s.Attr("fixed_version_id") != version {
+				continue
+			}
+			d := &r.Days[n]
+			status := s.AttrId("status_id")
+			d.Statuses[status]++
+			if containsInt(closedStatuses, status) {
+				d.Closed++
+				continue
+			}
+			d.Open++
+			if h, err := strconv.ParseFloat(s.Attr("estimated_hours"), 64); err == nil {
+				d.RemainingHours += h
+			}
+		}
+	}
+	return r, nil
+}
+
+// WriteCSV writes the report as CSV with one row per day and one column per
+// status, named after statusNames when known.
+func (r *VersionReport) WriteCSV(w io.Writer, statusNames map[int]string) error {
+	var ids []int
+	seen := map[int]bool{}
+	for _, d := range r.Days {
+		for id := range d.Statuses {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Ints(ids)
+
+	cw := csv.NewWriter(w)
+	header := []string{"date", "open", "closed", "remaining_hours"}
+	for _, id := range ids {
+		name, ok := statusNames[id]
+		if !ok {
+			name = strconv.Itoa(id)
+		}
+		header = append(header, name)
+	}
+	cw.Write(header)
+	for _, d := range r.Days {
+		record := []string{d.Date, strconv.Itoa(d.Open), strconv.Itoa(d.Closed),
+			strconv.FormatFloat(d.RemainingHours, 'f', -1, 64)}
+		for _, id := range ids {
+			record = append(record, strconv.Itoa(d.Statuses[id]))
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
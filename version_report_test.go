@@ -0,0 +1,50 @@
+package redmine
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// versionIssues are two issues of version 2: the first is closed on the
+// 3rd, the second is moved to version 9 on the 2nd.
+const versionIssues = `[
+	{"id": 1, "status": {"id": 5}, "fixed_version": {"id": 2}, "estimated_hours": 3,
+		"created_on": "2024-01-01T10:00:00Z", "journals": [
+			{"id": 1, "created_on": "2024-01-03T10:00:00Z", "details": [
+				{"property": "attr", "name": "status_id", "old_value": "1", "new_value": "5"}
+			]}
+		]},
+	{"id": 2, "status": {"id": 1}, "fixed_version": {"id": 9}, "estimated_hours": 2,
+		"created_on": "2024-01-01T10:00:00Z", "journals": [
+			{"id": 2, "created_on": "2024-01-02T10:00:00Z", "details": [
+				{"property": "attr", "name": "fixed_version_id", "old_value": "2", "new_value": "9"}
+			]}
+		]}
+]`
+
+func TestBuildVersionReport(t *testing.T) {
+	var issues []Issue
+	if err := json.Unmarshal([]byte(versionIssues), &issues); err != nil {
+		t.Fatal(err)
+	}
+	from, _ := time.Parse(time.RFC3339, "2023-12-31T00:00:00Z")
+	to, _ := time.Parse(time.RFC3339, "2024-01-03T00:00:00Z")
+	r, err := BuildVersionReport(2, issues, []int{5}, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []VersionDay{
+		{Date: "2023-12-31", Statuses: map[int]int{}},
+		{Date: "2024-01-01", Statuses: map[int]int{1: 2}, Open: 2, RemainingHours: 5},
+		{Date: "2024-01-02", Statuses: map[int]int{1: 1}, Open: 1, RemainingHours: 3},
+		{Date: "2024-01-03", Statuses: map[int]int{5: 1}, Closed: 1},
+	}
+	if !reflect.DeepEqual(r.Days, want) {
+		t.Errorf("Days = %+v, want %+v", r.Days, want)
+	}
+	if _, err := BuildVersionReport(2, issues, nil, to, from); err == nil {
+		t.Error("expected an error for an inverted range")
+	}
+}
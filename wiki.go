@@ -29,6 +29,9 @@ type WikiPage struct {
 	CreatedOn string      `json:"created_on,omitempty"`
 	UpdatedOn string      `json:"updated_on,omitempty"`
 	ParentID  int         `json:"parent_id"`
+
+	// ParentTitle sets the parent page when creating or updating a page.
+	ParentTitle string `json:"parent_title,omitempty"`
 }
 
 type Parent struct {